@@ -4,7 +4,10 @@ import (
 	"bytes"
 	"coinpaprika-telegram-bot/config"
 	"coinpaprika-telegram-bot/internal/alert"
+	"coinpaprika-telegram-bot/internal/commands"
 	"coinpaprika-telegram-bot/internal/database"
+	"coinpaprika-telegram-bot/internal/digest"
+	"coinpaprika-telegram-bot/internal/events"
 	"coinpaprika-telegram-bot/internal/price"
 	"coinpaprika-telegram-bot/internal/telegram"
 	"fmt"
@@ -23,6 +26,8 @@ import (
 	"sync"
 	"syscall"
 	"time"
+	// Embedded timezone database for digest schedules on hosts without zoneinfo
+	_ "time/tzdata"
 )
 
 type BotMetrics struct {
@@ -106,6 +111,8 @@ func main() {
 	LoadMetricsFromDB()
 
 	price.StartPriceUpdater()
+	commands.StartGlobalSnapshotService()
+	commands.StartPortfolioSnapshotService()
 	bot, err := telegram.NewBot(telegram.BotConfig{
 		Token:          config.GetString("telegram_bot_token"),
 		Debug:          config.GetBool("debug"),
@@ -116,7 +123,11 @@ func main() {
 		log.Fatalf("Failed to create bot: %v", err)
 	}
 
+	bot.RegisterCommands()
+
 	alert.StartAlertService(bot)
+	events.StartEventService(bot)
+	digest.StartDigestService(bot)
 
 	updates, err := bot.GetUpdatesChannel()
 	if err != nil {
@@ -161,6 +172,11 @@ func handleUpdates(bot *telegram.Bot, updates tgbotapi.UpdatesChannel) {
 			continue
 		}
 
+		if update.InlineQuery != nil {
+			bot.HandleInlineQuery(update.InlineQuery)
+			continue
+		}
+
 		if update.Message == nil {
 			log.Debug("Received non-message or non-command")
 			continue
@@ -171,7 +187,8 @@ func handleUpdates(bot *telegram.Bot, updates tgbotapi.UpdatesChannel) {
 			continue
 		}
 
-		if update.Message.IsCommand() == false && (len(update.Message.Text) == 0 || update.Message.Text[0] != '$') {
+		if update.Message.IsCommand() == false && (len(update.Message.Text) == 0 || update.Message.Text[0] != '$') &&
+			len(telegram.ExtractCashtags(update.Message)) == 0 && !commands.IsContractAddress(update.Message.Text) {
 			continue
 		}
 
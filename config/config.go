@@ -16,10 +16,18 @@ func InitConfig() {
 		viper.BindEnv("api_pro_key", "API_PRO_KEY")
 		viper.BindEnv("debug", "DEBUG")
 		viper.BindEnv("lang", "LANG")
+		viper.BindEnv("events_notify_hour", "EVENTS_NOTIFY_HOUR")
+		viper.BindEnv("max_cashtags", "MAX_CASHTAGS")
+		viper.BindEnv("cashtags_mode", "CASHTAGS_MODE")
+		viper.BindEnv("digest_timezone", "DIGEST_TIMEZONE")
 
 		viper.SetDefault("metrics_port", 9090)
 		viper.SetDefault("debug", false)
 		viper.SetDefault("lang", "en")
+		viper.SetDefault("events_notify_hour", 8)
+		viper.SetDefault("max_cashtags", 5)
+		viper.SetDefault("cashtags_mode", "table")
+		viper.SetDefault("digest_timezone", "UTC")
 	})
 }
 
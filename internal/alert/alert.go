@@ -4,10 +4,12 @@ import (
 	"coinpaprika-telegram-bot/internal/database"
 	"coinpaprika-telegram-bot/internal/price"
 	"coinpaprika-telegram-bot/internal/telegram"
+	"coinpaprika-telegram-bot/internal/types"
 	"coinpaprika-telegram-bot/lib/helpers"
 	"fmt"
 	"log"
 	"math"
+	"strconv"
 	"sync"
 	"time"
 )
@@ -27,12 +29,24 @@ func CheckAlerts(bot *telegram.Bot) {
 
 	log.Println("🔄 Checking alerts...")
 
+	expireAlerts(bot, time.Now().UTC())
+
 	alerts, err := database.GetAllAlerts()
 	if err != nil {
 		log.Printf("❌ Failed to fetch alerts from the database: %v\n", err)
 		return
 	}
 
+	// Move alerts need the recent prices of their coins, which are only kept for the tracked ones
+	var windowTickers []string
+	for _, alert := range alerts {
+		if alert.AlertType == "window" {
+			windowTickers = append(windowTickers, alert.Ticker)
+		}
+	}
+	price.TrackHistory(windowTickers)
+
+	now := time.Now().UTC()
 	for _, alert := range alerts {
 		priceInfo, exists := price.GetPrice(alert.Ticker)
 		if !exists {
@@ -40,69 +54,271 @@ func CheckAlerts(bot *telegram.Bot) {
 			continue
 		}
 
-		referencePrice := alert.CurrentPrice
-		if err != nil {
-			log.Printf("❌ Failed to parse current price for alert ID: %d | Error: %v\n", alert.ID, err)
+		// Pair alerts watch the ratio of the two prices in place of the USD price
+		if alert.QuoteTicker != "" {
+			ratio, found := price.GetRatio(alert.Ticker, alert.QuoteTicker)
+			if !found {
+				log.Printf("⚠️ No price data found for quote ticker: %s\n", alert.QuoteTicker)
+				continue
+			}
+			quoteInfo, _ := price.GetPrice(alert.QuoteTicker)
+			priceInfo.Symbol += "/" + quoteInfo.Symbol
+			priceInfo.PriceUSD = ratio
+		}
+
+		value, threshold, rising, ok := alertThreshold(alert, priceInfo)
+		if !ok {
 			continue
 		}
 
-		if alert.AlertType == "price" {
+		var message string
+		if alert.QuoteTicker != "" && alert.AlertType == "price" {
+			log.Printf("🔍 Checking ratio alert ID: %d | Pair: %s | Target: %g | Current: %g\n",
+				alert.ID, priceInfo.Symbol, alert.Target, priceInfo.PriceUSD)
+
+			message = fmt.Sprintf(
+				"🚨 *Ratio Alert Triggered*\n\n*%s* has reached the target ratio of *%s*\nCurrent Ratio: *%s*",
+				helpers.EscapeMarkdownV2(priceInfo.Symbol),
+				helpers.FormatPriceUS(alert.Target, true),
+				helpers.FormatPriceUS(priceInfo.PriceUSD, true),
+			)
+		} else if alert.QuoteTicker != "" && alert.AlertType == "percent" {
+			ratioChange := ((priceInfo.PriceUSD - alert.CurrentPrice) / alert.CurrentPrice) * 100
+
+			log.Printf("🔍 Checking ratio percent alert ID: %d | Pair: %s | Target: %.2f%% | Current Change: %.2f%%\n",
+				alert.ID, priceInfo.Symbol, alert.Target, ratioChange)
+
+			message = fmt.Sprintf(
+				"🚨 *Ratio Alert Triggered*\n\n*%s* ratio has reached the target change of *%s%%*\nCurrent Change: *%s%%*",
+				helpers.EscapeMarkdownV2(priceInfo.Symbol),
+				helpers.EscapeMarkdownV2(fmt.Sprintf("%.2f", alert.Target)),
+				helpers.EscapeMarkdownV2(fmt.Sprintf("%.2f", ratioChange)),
+			)
+		} else if alert.AlertType == "price" {
 			log.Printf("🔍 Checking price alert ID: %d | Ticker: %s | Target: %.2f | Current: %.2f\n",
 				alert.ID, alert.Ticker, alert.Target, priceInfo.PriceUSD)
 
-			if (alert.Target > alert.CurrentPrice && priceInfo.PriceUSD >= alert.Target) || (alert.Target < alert.CurrentPrice && priceInfo.PriceUSD <= alert.Target) {
-				message := fmt.Sprintf(
-					"🚨 *Price Alert Triggered*\n\n*%s \\(%s\\)* has reached the target price of *$%s*\nCurrent Price: *$%s*",
-					helpers.EscapeMarkdownV2(priceInfo.Name),
-					helpers.EscapeMarkdownV2(priceInfo.Symbol),
-					helpers.FormatPriceRoundedUS(math.Round(alert.Target)),
-					helpers.FormatPriceRoundedUS(math.Round(priceInfo.PriceUSD)),
+			message = fmt.Sprintf(
+				"🚨 *Price Alert Triggered*\n\n*%s \\(%s\\)* has reached the target price of *$%s*\nCurrent Price: *$%s*",
+				helpers.EscapeMarkdownV2(priceInfo.Name),
+				helpers.EscapeMarkdownV2(priceInfo.Symbol),
+				helpers.FormatPriceRoundedUS(math.Round(alert.Target)),
+				helpers.FormatPriceRoundedUS(math.Round(priceInfo.PriceUSD)),
+			)
+		} else if alert.AlertType == "percent" {
+			percentageChange := ((priceInfo.PriceUSD - alert.CurrentPrice) / alert.CurrentPrice) * 100
+
+			log.Printf("🔍 Checking percent alert ID: %d | Ticker: %s | Target: %.2f%% | Current Change: %.2f%%\n",
+				alert.ID, alert.Ticker, alert.Target, percentageChange)
+
+			message = fmt.Sprintf(
+				"🚨 *Percent Alert Triggered*\n\n*%s \\(%s\\)* has reached the target change of *%s%%*\nCurrent Change: *%s%%*",
+				helpers.EscapeMarkdownV2(priceInfo.Name),
+				helpers.EscapeMarkdownV2(priceInfo.Symbol),
+				helpers.EscapeMarkdownV2(fmt.Sprintf("%.2f", alert.Target)),
+				helpers.EscapeMarkdownV2(fmt.Sprintf("%.2f", percentageChange)),
+			)
+		} else if alert.AlertType == "window" {
+			window := time.Duration(alert.Window) * time.Second
+
+			log.Printf("🔍 Checking move alert ID: %d | Ticker: %s | Target: %.2f%% in %s | Current Move: %.2f%%\n",
+				alert.ID, alert.Ticker, alert.Target, helpers.FormatInterval(window), value)
+
+			message = fmt.Sprintf(
+				"🚨 *Move Alert Triggered*\n\n*%s \\(%s\\)* has moved *%s%%* within *%s*\nCurrent Price: *$%s*",
+				helpers.EscapeMarkdownV2(priceInfo.Name),
+				helpers.EscapeMarkdownV2(priceInfo.Symbol),
+				helpers.EscapeMarkdownV2(fmt.Sprintf("%+.2f", value)),
+				helpers.FormatInterval(window),
+				helpers.FormatPriceUS(priceInfo.PriceUSD, true),
+			)
+		} else if alert.AlertType == "volume" || alert.AlertType == "volume_percent" {
+			log.Printf("🔍 Checking volume alert ID: %d | Ticker: %s | Threshold: %.0f | Current: %.0f\n",
+				alert.ID, alert.Ticker, threshold, priceInfo.Volume24h)
+
+			message = fmt.Sprintf(
+				"🚨 *Volume Alert Triggered*\n\n*%s \\(%s\\)* 24h volume is above *$%s*\nCurrent Volume: *$%s*",
+				helpers.EscapeMarkdownV2(priceInfo.Name),
+				helpers.EscapeMarkdownV2(priceInfo.Symbol),
+				helpers.EscapeMarkdownV2(helpers.FormatCompactUS(threshold)),
+				helpers.EscapeMarkdownV2(helpers.FormatCompactUS(priceInfo.Volume24h)),
+			)
+			if alert.AlertType == "volume_percent" {
+				message += fmt.Sprintf(
+					"\n*%s%%* vs the 7d average of *$%s*",
+					helpers.EscapeMarkdownV2(fmt.Sprintf("%+.1f", (priceInfo.Volume24h-alert.CurrentPrice)/alert.CurrentPrice*100)),
+					helpers.EscapeMarkdownV2(helpers.FormatCompactUS(alert.CurrentPrice)),
 				)
+			}
+		} else if alert.AlertType == "mcap" {
+			log.Printf("🔍 Checking market cap alert ID: %d | Ticker: %s | Target: %.0f | Current: %.0f\n",
+				alert.ID, alert.Ticker, alert.Target, priceInfo.MarketCap)
+
+			message = fmt.Sprintf(
+				"🚨 *Market Cap Alert Triggered*\n\n*%s \\(%s\\)* has reached the target market cap of *$%s*\nCurrent Market Cap: *$%s*",
+				helpers.EscapeMarkdownV2(priceInfo.Name),
+				helpers.EscapeMarkdownV2(priceInfo.Symbol),
+				helpers.EscapeMarkdownV2(helpers.FormatCompactUS(alert.Target)),
+				helpers.EscapeMarkdownV2(helpers.FormatCompactUS(priceInfo.MarketCap)),
+			)
+		} else if alert.AlertType == "rank" {
+			log.Printf("🔍 Checking rank alert ID: %d | Ticker: %s | Top: %.0f | Current: %d\n",
+				alert.ID, alert.Ticker, alert.Target, priceInfo.Rank)
+
+			movement := "entered"
+			if rising {
+				movement = "left"
+			}
+			message = fmt.Sprintf(
+				"🚨 *Rank Alert Triggered*\n\n*%s \\(%s\\)* has %s the top *%d*\nCurrent Rank: *\\#%d*",
+				helpers.EscapeMarkdownV2(priceInfo.Name),
+				helpers.EscapeMarkdownV2(priceInfo.Symbol),
+				movement,
+				int(alert.Target),
+				priceInfo.Rank,
+			)
+		} else {
+			continue
+		}
 
-				err := bot.SendMessage(telegram.Message{
-					ChatID: int(alert.ChatID),
-					Text:   message,
-				})
-				if err != nil {
-					log.Printf("❌ Failed to send price alert notification: %v\n", err)
+		reached := (rising && value >= threshold) || (!rising && value <= threshold)
+		repeating := alert.RepeatInterval > 0
+
+		if repeating && !alert.Armed {
+			// A fired repeating alert re-arms once the value moved back across the threshold by the hysteresis margin
+			margin := math.Abs(threshold) * alert.Hysteresis / 100
+			if (rising && value <= threshold-margin) || (!rising && value >= threshold+margin) {
+				if err := database.RearmAlert(alert.ID); err != nil {
+					log.Printf("❌ Failed to re-arm alert ID: %d | Error: %v\n", alert.ID, err)
 				} else {
-					log.Printf("✅ Price alert notification sent to Chat ID: %d\n", alert.ChatID)
+					log.Printf("🔁 Alert ID: %d re-armed\n", alert.ID)
 				}
-
-				_ = database.DeleteAlert(alert.ID)
 			}
-		} else if alert.AlertType == "percent" {
-			percentageChange := ((priceInfo.PriceUSD - referencePrice) / referencePrice) * 100
-			targetPercent := alert.Target
+			continue
+		}
 
-			log.Printf("🔍 Checking percent alert ID: %d | Ticker: %s | Target: %.2f%% | Current Change: %.2f%%\n",
-				alert.ID, alert.Ticker, targetPercent, percentageChange)
-
-			if (targetPercent > 0 && percentageChange >= targetPercent) || (targetPercent < 0 && percentageChange <= targetPercent) {
-				message := fmt.Sprintf(
-					"🚨 *Percent Alert Triggered*\n\n*%s \\(%s\\)* has reached the target change of *%s%%*\nCurrent Change: *%s%%*",
-					helpers.EscapeMarkdownV2(priceInfo.Name),
-					helpers.EscapeMarkdownV2(priceInfo.Symbol),
-					helpers.EscapeMarkdownV2(fmt.Sprintf("%.2f", targetPercent)),
-					helpers.EscapeMarkdownV2(fmt.Sprintf("%.2f", percentageChange)),
-				)
+		if !reached {
+			continue
+		}
+
+		cooldown := time.Duration(alert.RepeatInterval) * time.Second
+		if repeating && !alert.LastTriggeredAt.IsZero() && now.Sub(alert.LastTriggeredAt) < cooldown {
+			log.Printf("⏳ Alert ID: %d is cooling down until %s\n", alert.ID, alert.LastTriggeredAt.Add(cooldown).Format(time.RFC3339))
+			continue
+		}
+
+		if repeating {
+			message += fmt.Sprintf(
+				"\n\n🔁 Repeats at most every %s once it moves back by %s%%",
+				helpers.FormatInterval(cooldown),
+				helpers.EscapeMarkdownV2(strconv.FormatFloat(alert.Hysteresis, 'f', -1, 64)),
+			)
+		}
+
+		err := bot.SendMessage(telegram.Message{
+			ChatID: int(alert.ChatID),
+			Text:   message,
+		})
+		if err != nil {
+			log.Printf("❌ Failed to send %s alert notification: %v\n", alert.AlertType, err)
+		} else {
+			log.Printf("✅ %s alert notification sent to Chat ID: %d\n", alert.AlertType, alert.ChatID)
+		}
 
-				err := bot.SendMessage(telegram.Message{
-					ChatID: int(alert.ChatID),
-					Text:   message,
-				})
-				if err != nil {
-					log.Printf("❌ Failed to send percent alert notification: %v\n", err)
+		if repeating {
+			_ = database.MarkAlertTriggered(alert.ID, now)
+		} else {
+			_ = database.DeleteAlert(alert.ID)
+		}
+	}
+
+	log.Println("✅ Alert check completed.")
+}
+
+// expireAlerts removes the alerts past their expiration and tells the chats about the ones which never triggered
+func expireAlerts(bot *telegram.Bot, now time.Time) {
+	alerts, err := database.GetExpiredAlerts(now)
+	if err != nil {
+		log.Printf("❌ Failed to fetch expired alerts from the database: %v\n", err)
+		return
+	}
+
+	for _, alert := range alerts {
+		if alert.NotifyExpiry && alert.LastTriggeredAt.IsZero() {
+			name := alert.Ticker
+			if priceInfo, exists := price.GetPrice(alert.Ticker); exists {
+				name = priceInfo.Symbol
+			}
+			if alert.QuoteTicker != "" {
+				if quoteInfo, exists := price.GetPrice(alert.QuoteTicker); exists {
+					name += "/" + quoteInfo.Symbol
 				} else {
-					log.Printf("✅ Percent alert notification sent to Chat ID: %d\n", alert.ChatID)
+					name += "/" + alert.QuoteTicker
 				}
+			}
 
-				_ = database.DeleteAlert(alert.ID)
+			message := fmt.Sprintf(
+				"⌛ *Alert Expired*\n\nAlert \\#%d for *%s* set on %s expired without triggering",
+				alert.ID,
+				helpers.EscapeMarkdownV2(name),
+				helpers.EscapeMarkdownV2(helpers.FormatDate(alert.CreatedAt)),
+			)
+			err := bot.SendMessage(telegram.Message{
+				ChatID: int(alert.ChatID),
+				Text:   message,
+			})
+			if err != nil {
+				log.Printf("❌ Failed to send expiry notification: %v\n", err)
 			}
 		}
+
+		if err := database.DeleteAlert(alert.ID); err != nil {
+			log.Printf("❌ Failed to delete expired alert ID: %d | Error: %v\n", alert.ID, err)
+		} else {
+			log.Printf("⌛ Alert ID: %d expired\n", alert.ID)
+		}
+	}
+}
+
+// alertThreshold returns the current value of the metric an alert watches, the value at which it fires and whether
+// it fires on the way up. Alerts whose target equals their reference never fire.
+func alertThreshold(alert types.Alert, p price.PriceInfo) (float64, float64, bool, bool) {
+	switch alert.AlertType {
+	case "price":
+		return p.PriceUSD, alert.Target, alert.Target > alert.CurrentPrice, alert.Target != alert.CurrentPrice
+	case "percent":
+		return p.PriceUSD, alert.CurrentPrice * (1 + alert.Target/100), alert.Target > 0, alert.Target != 0
+	case "window":
+		move, ok := windowMove(alert.Ticker, time.Duration(alert.Window)*time.Second, p.PriceUSD, alert.Target > 0)
+		return move, alert.Target, alert.Target > 0, ok && alert.Target != 0
+	case "volume":
+		return p.Volume24h, alert.Target, true, true
+	case "volume_percent":
+		return p.Volume24h, alert.CurrentPrice * (1 + alert.Target/100), true, alert.CurrentPrice > 0
+	case "mcap":
+		return p.MarketCap, alert.Target, alert.Target > alert.CurrentPrice, alert.Target != alert.CurrentPrice
+	case "rank":
+		// The threshold sits between two ranks: a coin outside the top N fires on entering it, one inside on leaving it
+		return float64(p.Rank), alert.Target + 0.5, alert.CurrentPrice <= alert.Target, p.Rank > 0
 	}
+	return 0, 0, false, false
+}
 
-	log.Println("✅ Alert check completed.")
+// windowMove returns the percent move of the current price from the lowest price within the trailing window,
+// or from the highest one for a downward move
+func windowMove(tickerID string, window time.Duration, current float64, up bool) (float64, bool) {
+	points, tracked := price.GetPriceHistory(tickerID, window)
+	if !tracked || len(points) < 2 || current <= 0 {
+		return 0, false
+	}
+
+	extreme := points[0].PriceUSD
+	for _, point := range points[1:] {
+		if (up && point.PriceUSD < extreme) || (!up && point.PriceUSD > extreme) {
+			extreme = point.PriceUSD
+		}
+	}
+	return (current - extreme) / extreme * 100, true
 }
 
 // StartAlertService starts a background service to check alerts every minute
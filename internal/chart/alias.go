@@ -44,6 +44,8 @@ const (
 	ChartTypePie    = "pie"
 	ChartTypeRadar  = "radar"
 	ChartTypeFunnel = "funnel"
+	// heatmap of category rows and columns
+	ChartTypeHeatmap = "heatmap"
 	// horizontal bar
 	ChartTypeHorizontalBar = "horizontalBar"
 )
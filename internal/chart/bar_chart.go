@@ -0,0 +1,187 @@
+// MIT License
+
+// Copyright (c) 2022 Tree Xie
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package chart
+
+import (
+	"github.com/golang/freetype/truetype"
+)
+
+type barChart struct {
+	p   *Painter
+	opt *BarChartOption
+}
+
+// NewBarChart returns a bar chart renderer
+func NewBarChart(p *Painter, opt BarChartOption) *barChart {
+	if opt.Theme == nil {
+		opt.Theme = defaultTheme
+	}
+	return &barChart{
+		p:   p,
+		opt: &opt,
+	}
+}
+
+type BarChartOption struct {
+	// The theme
+	Theme ColorPalette
+	// The font size
+	Font *truetype.Font
+	// The data series list
+	SeriesList SeriesList
+	// The x axis option
+	XAxis XAxisOption
+	// The padding of bar chart
+	Padding Box
+	// The y axis option
+	YAxisOptions []YAxisOption
+	// The option of title
+	Title TitleOption
+	// The legend option
+	Legend LegendOption
+	// background is filled
+	backgroundIsFilled bool
+}
+
+func (b *barChart) render(result *defaultRenderResult, seriesList SeriesList) (Box, error) {
+	p := b.p
+	opt := b.opt
+	seriesPainter := result.seriesPainter
+
+	xRange := NewRange(AxisRangeOption{
+		Painter:     p,
+		DivideCount: len(opt.XAxis.Data),
+		Size:        seriesPainter.Width(),
+	})
+	x0, x1 := xRange.GetRange(0)
+	width := int(x1 - x0)
+	// The gap between categories and between the bars of one category
+	margin := 10
+	if width <= 80 {
+		margin = 5
+	}
+	if width <= 10 {
+		margin = 1
+	}
+	barMargin := 5
+	if width <= 20 {
+		barMargin = 1
+	}
+	seriesCount := len(seriesList)
+	if seriesCount == 0 {
+		return p.box, nil
+	}
+	barWidth := (width - 2*margin - barMargin*(seriesCount-1)) / seriesCount
+	if barWidth < 1 {
+		barWidth = 1
+	}
+
+	seriesNames := seriesList.Names()
+	rendererList := []Renderer{}
+	divideValues := xRange.AutoDivide()
+	for index := range seriesList {
+		series := seriesList[index]
+		yRange := result.axisRanges[series.AxisIndex]
+		seriesColor := opt.Theme.GetSeriesColor(series.index)
+		// Bars grow from zero, or from the bottom of the axis when it does not include zero
+		baseValue := yRange.min
+		if baseValue < 0 && yRange.max > 0 {
+			baseValue = 0
+		}
+		baseY := yRange.getRestHeight(baseValue)
+
+		var labelPainter *SeriesLabelPainter
+		if series.Label.Show {
+			labelPainter = NewSeriesLabelPainter(SeriesLabelPainterParams{
+				P:           seriesPainter,
+				SeriesNames: seriesNames,
+				Label:       series.Label,
+				Theme:       opt.Theme,
+				Font:        opt.Font,
+			})
+			rendererList = append(rendererList, labelPainter)
+		}
+
+		for j, item := range series.Data {
+			if j >= xRange.divideCount {
+				continue
+			}
+			x := divideValues[j] + margin + index*(barWidth+barMargin)
+			y := yRange.getRestHeight(item.Value)
+			top, bottom := y, baseY
+			if top > bottom {
+				top, bottom = bottom, top
+			}
+
+			fillColor := seriesColor
+			if !item.Style.FillColor.IsZero() {
+				fillColor = item.Style.FillColor
+			}
+			seriesPainter.OverrideDrawingStyle(Style{
+				FillColor: fillColor,
+			}).Rect(Box{
+				Top:    top,
+				Left:   x,
+				Right:  x + barWidth,
+				Bottom: bottom,
+			})
+
+			if labelPainter == nil {
+				continue
+			}
+			labelPainter.Add(LabelValue{
+				Index:    index,
+				Value:    item.Value,
+				X:        x + barWidth>>1,
+				Y:        top,
+				FontSize: series.Label.FontSize,
+			})
+		}
+	}
+
+	err := doRender(rendererList...)
+	if err != nil {
+		return BoxZero, err
+	}
+	return p.box, nil
+}
+
+func (b *barChart) Render() (Box, error) {
+	p := b.p
+	opt := b.opt
+	renderResult, err := defaultRender(p, defaultRenderOption{
+		Theme:              opt.Theme,
+		Padding:            opt.Padding,
+		SeriesList:         opt.SeriesList,
+		XAxis:              opt.XAxis,
+		YAxisOptions:       opt.YAxisOptions,
+		TitleOption:        opt.Title,
+		LegendOption:       opt.Legend,
+		backgroundIsFilled: opt.backgroundIsFilled,
+	})
+	if err != nil {
+		return BoxZero, err
+	}
+	seriesList := opt.SeriesList.Filter(ChartTypeBar)
+	return b.render(renderResult, seriesList)
+}
@@ -73,8 +73,12 @@ type ChartOption struct {
 	BarHeight int
 	// Fill the area of line chart
 	FillArea bool
+	// Stack the filled areas of the line series on top of each other
+	StackArea bool
 	// background fill (alpha) opacity
 	Opacity uint8
+	// The value range and colors of heatmap cells
+	VisualMap VisualMapOption
 	// The child charts
 	Children []ChartOption
 	// The value formatter
@@ -318,3 +322,19 @@ func LineRender(values [][]float64, opts ...OptionFunc) (*Painter, error) {
 		SeriesList: seriesList,
 	}, opts...)
 }
+
+// BarRender bar chart render
+func BarRender(values [][]float64, opts ...OptionFunc) (*Painter, error) {
+	seriesList := NewSeriesListDataFromValues(values, ChartTypeBar)
+	return Render(ChartOption{
+		SeriesList: seriesList,
+	}, opts...)
+}
+
+// HeatmapRender heatmap chart render, every row of values is a row of cells
+func HeatmapRender(values [][]float64, opts ...OptionFunc) (*Painter, error) {
+	seriesList := NewSeriesListDataFromValues(values, ChartTypeHeatmap)
+	return Render(ChartOption{
+		SeriesList: seriesList,
+	}, opts...)
+}
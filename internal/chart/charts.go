@@ -309,11 +309,33 @@ func Render(opt ChartOption, opts ...OptionFunc) (*Painter, error) {
 	if !isChild {
 		p.SetBackground(p.Width(), p.Height(), opt.BackgroundColor)
 	}
+	if opt.StackArea {
+		opt.SeriesList = opt.SeriesList.Stacked()
+	}
 	seriesList := opt.SeriesList
 	seriesList.init()
 
 	// line chart
 	lineSeriesList := seriesList.Filter(ChartTypeLine)
+	barSeriesList := seriesList.Filter(ChartTypeBar)
+
+	// heatmap chart, its rows and columns are categories so there are no value axes to render
+	if heatmapSeriesList := seriesList.Filter(ChartTypeHeatmap); len(heatmapSeriesList) != 0 {
+		_, err := NewHeatmapChart(p, HeatmapChartOption{
+			Theme:              opt.theme,
+			Font:               opt.font,
+			SeriesList:         heatmapSeriesList,
+			XAxis:              opt.XAxis,
+			Title:              opt.Title,
+			VisualMap:          opt.VisualMap,
+			ValueFormatter:     opt.ValueFormatter,
+			backgroundIsFilled: true,
+		}).Render()
+		if err != nil {
+			return nil, err
+		}
+		return p, nil
+	}
 
 	renderOpt := defaultRenderOption{
 		Theme:        opt.theme,
@@ -344,12 +366,25 @@ func Render(opt ChartOption, opts ...OptionFunc) (*Painter, error) {
 				SymbolShow:  opt.SymbolShow,
 				StrokeWidth: opt.LineStrokeWidth,
 				FillArea:    opt.FillArea,
+				StackArea:   opt.StackArea,
 				Opacity:     opt.Opacity,
 			}).render(renderResult, lineSeriesList)
 			return err
 		})
 	}
 
+	// bar chart
+	if len(barSeriesList) != 0 {
+		handler.Add(func() error {
+			_, err := NewBarChart(p, BarChartOption{
+				Theme: opt.theme,
+				Font:  opt.font,
+				XAxis: opt.XAxis,
+			}).render(renderResult, barSeriesList)
+			return err
+		})
+	}
+
 	err = handler.Do()
 
 	if err != nil {
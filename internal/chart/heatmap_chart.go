@@ -0,0 +1,217 @@
+// MIT License
+
+// Copyright (c) 2022 Tree Xie
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package chart
+
+import (
+	"math"
+
+	"github.com/golang/freetype/truetype"
+)
+
+type heatmapChart struct {
+	p   *Painter
+	opt *HeatmapChartOption
+}
+
+// NewHeatmapChart returns a heatmap chart renderer
+func NewHeatmapChart(p *Painter, opt HeatmapChartOption) *heatmapChart {
+	if opt.Theme == nil {
+		opt.Theme = defaultTheme
+	}
+	return &heatmapChart{
+		p:   p,
+		opt: &opt,
+	}
+}
+
+type HeatmapChartOption struct {
+	// The theme
+	Theme ColorPalette
+	// The font size
+	Font *truetype.Font
+	// The rows of the heatmap, the name of a series is the label of its row
+	SeriesList SeriesList
+	// The labels of the columns
+	XAxis XAxisOption
+	// The padding of heatmap chart
+	Padding Box
+	// The option of title
+	Title TitleOption
+	// The value range and colors of the cells
+	VisualMap VisualMapOption
+	// The formatter of the cell values, the values are not shown when nil
+	ValueFormatter ValueFormatter
+	// background is filled
+	backgroundIsFilled bool
+}
+
+type VisualMapOption struct {
+	// The value mapped to the first color
+	Min float64
+	// The value mapped to the last color, the range of the data is used when Max is not above Min
+	Max float64
+	// The colors the cells are interpolated between, from Min to Max
+	Colors []Color
+}
+
+// cellGap is the space between two heatmap cells
+const cellGap = 2
+
+// colorOf interpolates the color of a value between the colors of the visual map
+func (v VisualMapOption) colorOf(value float64) Color {
+	if len(v.Colors) == 0 {
+		return Color{}
+	}
+	if len(v.Colors) == 1 || v.Max <= v.Min {
+		return v.Colors[0]
+	}
+
+	position := (value - v.Min) / (v.Max - v.Min) * float64(len(v.Colors)-1)
+	position = math.Max(0, math.Min(position, float64(len(v.Colors)-1)))
+	index := int(position)
+	if index == len(v.Colors)-1 {
+		return v.Colors[index]
+	}
+
+	ratio := position - float64(index)
+	from, to := v.Colors[index], v.Colors[index+1]
+	blend := func(a, b uint8) uint8 {
+		return uint8(math.Round(float64(a) + (float64(b)-float64(a))*ratio))
+	}
+	return Color{
+		R: blend(from.R, to.R),
+		G: blend(from.G, to.G),
+		B: blend(from.B, to.B),
+		A: blend(from.A, to.A),
+	}
+}
+
+func (h *heatmapChart) render(seriesList SeriesList) (Box, error) {
+	p := h.p
+	opt := h.opt
+	if len(seriesList) == 0 || len(opt.XAxis.Data) == 0 {
+		return p.box, nil
+	}
+
+	visualMap := opt.VisualMap
+	if visualMap.Max <= visualMap.Min {
+		visualMap.Max, visualMap.Min = seriesList.GetMaxMin(0)
+	}
+	if len(visualMap.Colors) == 0 {
+		visualMap.Colors = []Color{
+			opt.Theme.GetBackgroundColor(),
+			opt.Theme.GetSeriesColor(0),
+		}
+	}
+
+	textStyle := Style{
+		Font:      opt.Font,
+		FontSize:  opt.Theme.GetFontSize(),
+		FontColor: opt.Theme.GetTextColor(),
+	}
+	p.SetTextStyle(textStyle)
+
+	rowLabels := seriesList.Names()
+	rowLabelWidth, _ := p.MeasureTextMaxWidthHeight(rowLabels)
+	_, columnLabelHeight := p.MeasureTextMaxWidthHeight(opt.XAxis.Data)
+	left := rowLabelWidth + 10
+	bottom := columnLabelHeight + 10
+
+	columns := len(opt.XAxis.Data)
+	cellWidth := (p.Width() - left) / columns
+	cellHeight := (p.Height() - bottom) / len(seriesList)
+
+	for row, series := range seriesList {
+		top := row * cellHeight
+
+		labelBox := p.MeasureText(rowLabels[row])
+		p.OverrideTextStyle(textStyle).Text(rowLabels[row], rowLabelWidth-labelBox.Width(), top+(cellHeight+labelBox.Height())>>1)
+
+		for column, item := range series.Data {
+			if column >= columns || item.Value == nullValue {
+				continue
+			}
+			x := left + column*cellWidth
+			fillColor := visualMap.colorOf(item.Value)
+			p.OverrideDrawingStyle(Style{
+				FillColor:   fillColor,
+				StrokeColor: fillColor,
+				StrokeWidth: 1,
+			}).Rect(Box{
+				Top:    top + cellGap,
+				Left:   x + cellGap,
+				Right:  x + cellWidth - cellGap,
+				Bottom: top + cellHeight - cellGap,
+			})
+
+			if opt.ValueFormatter == nil {
+				continue
+			}
+			value := opt.ValueFormatter(item.Value)
+			valueStyle := textStyle
+			valueStyle.FontColor = Color{R: 255, G: 255, B: 255, A: 255}
+			if isLightColor(fillColor) {
+				valueStyle.FontColor = Color{R: 40, G: 40, B: 40, A: 255}
+			}
+			p.OverrideTextStyle(valueStyle)
+			valueBox := p.MeasureText(value)
+			p.Text(value, x+(cellWidth-valueBox.Width())>>1, top+(cellHeight+valueBox.Height())>>1)
+		}
+	}
+
+	p.OverrideTextStyle(textStyle)
+	labelTop := len(seriesList)*cellHeight + 10
+	for column, label := range opt.XAxis.Data {
+		labelBox := p.MeasureText(label)
+		p.Text(label, left+column*cellWidth+(cellWidth-labelBox.Width())>>1, labelTop+labelBox.Height())
+	}
+
+	return p.box, nil
+}
+
+func (h *heatmapChart) Render() (Box, error) {
+	p := h.p
+	opt := h.opt
+	if !opt.backgroundIsFilled {
+		p.SetBackground(p.Width(), p.Height(), opt.Theme.GetBackgroundColor())
+	}
+	if !opt.Padding.IsZero() {
+		p = p.Child(PainterPaddingOption(opt.Padding))
+	}
+
+	if opt.Title.Text != "" {
+		if opt.Title.Theme == nil {
+			opt.Title.Theme = opt.Theme
+		}
+		titleBox, err := NewTitlePainter(p, opt.Title).Render()
+		if err != nil {
+			return BoxZero, err
+		}
+		p = p.Child(PainterPaddingOption(Box{
+			Top: titleBox.Height() + 20,
+		}))
+	}
+
+	h.p = p
+	return h.render(opt.SeriesList.Filter(ChartTypeHeatmap))
+}
@@ -68,6 +68,8 @@ type LineChartOption struct {
 	StrokeWidth float64
 	// Fill the area of line
 	FillArea bool
+	// Stack the filled areas, each series is drawn on top of the previous one
+	StackArea bool
 	// background is filled
 	backgroundIsFilled bool
 	// background fill (alpha) opacity
@@ -108,6 +110,8 @@ func (l *lineChart) render(result *defaultRenderResult, seriesList SeriesList) (
 		strokeWidth = defaultStrokeWidth
 	}
 	seriesNames := seriesList.Names()
+	// The top edge of the previous series, stacked areas are filled down to it
+	var previousPoints []Point
 	for index := range seriesList {
 		series := seriesList[index]
 		seriesColor := opt.Theme.GetSeriesColor(series.index)
@@ -165,18 +169,26 @@ func (l *lineChart) render(result *defaultRenderResult, seriesList SeriesList) (
 			if opt.Opacity != 0 {
 				opacity = opt.Opacity
 			}
-			areaPoints = append(areaPoints, Point{
-				X: areaPoints[len(areaPoints)-1].X,
-				Y: bottomY,
-			}, Point{
-				X: areaPoints[0].X,
-				Y: bottomY,
-			}, areaPoints[0])
+			if opt.StackArea && len(previousPoints) == len(points) {
+				for i := len(previousPoints) - 1; i >= 0; i-- {
+					areaPoints = append(areaPoints, previousPoints[i])
+				}
+				areaPoints = append(areaPoints, areaPoints[0])
+			} else {
+				areaPoints = append(areaPoints, Point{
+					X: areaPoints[len(areaPoints)-1].X,
+					Y: bottomY,
+				}, Point{
+					X: areaPoints[0].X,
+					Y: bottomY,
+				}, areaPoints[0])
+			}
 			seriesPainter.SetDrawingStyle(Style{
 				FillColor: seriesColor.WithAlpha(opacity),
 			})
 			seriesPainter.FillArea(areaPoints)
 		}
+		previousPoints = points
 		seriesPainter.SetDrawingStyle(drawingStyle)
 
 		// 画线
@@ -220,6 +232,9 @@ func (l *lineChart) render(result *defaultRenderResult, seriesList SeriesList) (
 func (l *lineChart) Render() (Box, error) {
 	p := l.p
 	opt := l.opt
+	if opt.StackArea {
+		opt.SeriesList = opt.SeriesList.Stacked()
+	}
 
 	renderResult, err := defaultRender(p, defaultRenderOption{
 		Theme:              opt.Theme,
@@ -164,6 +164,31 @@ func (sl SeriesList) Filter(chartType string) SeriesList {
 	return arr
 }
 
+// Stacked returns a copy of the series list where every series is the running total of
+// the series before it, which is how stacked areas are drawn. Null values count as zero.
+func (sl SeriesList) Stacked() SeriesList {
+	result := make(SeriesList, len(sl))
+	var totals []float64
+	for index, series := range sl {
+		data := make([]SeriesData, len(series.Data))
+		for i, item := range series.Data {
+			for len(totals) <= i {
+				totals = append(totals, 0)
+			}
+			if item.Value != nullValue {
+				totals[i] += item.Value
+			}
+			data[i] = SeriesData{
+				Value: totals[i],
+				Style: item.Style,
+			}
+		}
+		series.Data = data
+		result[index] = series
+	}
+	return result
+}
+
 // GetMaxMin get max and min value of series list
 func (sl SeriesList) GetMaxMin(axisIndex int) (float64, float64) {
 	min := math.MaxFloat64
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"sync"
 	"time"
 )
 
@@ -10,9 +11,16 @@ type CacheItem struct {
 	Expiration time.Time
 }
 
-var chartCache = make(map[string]*CacheItem)
+// The cache is shared by the update loop and the digest service, so every access goes through the mutex
+var (
+	chartCache      = make(map[string]*CacheItem)
+	chartCacheMutex = sync.RWMutex{}
+)
 
 func cacheGet(ticker string) (*CacheItem, bool) {
+	chartCacheMutex.RLock()
+	defer chartCacheMutex.RUnlock()
+
 	if item, found := chartCache[ticker]; found && time.Now().Before(item.Expiration) {
 		return item, true
 	}
@@ -20,9 +28,19 @@ func cacheGet(ticker string) (*CacheItem, bool) {
 }
 
 func cacheSet(ticker string, chartData []byte, caption string, duration time.Duration) {
+	chartCacheMutex.Lock()
+	defer chartCacheMutex.Unlock()
+
 	chartCache[ticker] = &CacheItem{
 		ChartData:  chartData,
 		Caption:    caption,
 		Expiration: time.Now().Add(duration),
 	}
 }
+
+func cacheDelete(ticker string) {
+	chartCacheMutex.Lock()
+	defer chartCacheMutex.Unlock()
+
+	delete(chartCache, ticker)
+}
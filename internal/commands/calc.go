@@ -0,0 +1,363 @@
+package commands
+
+import (
+	"coinpaprika-telegram-bot/internal/database"
+	"coinpaprika-telegram-bot/internal/price"
+	"coinpaprika-telegram-bot/lib/helpers"
+	"coinpaprika-telegram-bot/lib/translation"
+	"fmt"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ErrCalcSyntax is returned when a /calc expression cannot be parsed
+var ErrCalcSyntax = errors.New("invalid calc expression")
+
+// calcError is an evaluation error explained to the user by a catalog message
+type calcError struct {
+	msgID string
+	arg   string
+}
+
+func (e *calcError) Error() string {
+	return fmt.Sprintf("%s: %s", e.msgID, e.arg)
+}
+
+// calcValue is a number carrying its power of USD, so "btc" is 1 (a price), "sol/eth" is 0 (a ratio)
+// and adding a price to a plain number can be reported instead of silently mixing units
+type calcValue struct {
+	value float64
+	usd   int
+}
+
+type calcTokenKind int
+
+const (
+	calcNumber calcTokenKind = iota
+	calcIdent
+	calcOperator
+)
+
+type calcToken struct {
+	kind  calcTokenKind
+	text  string
+	value float64
+}
+
+// calcParser evaluates expressions of numbers, coins, + - * / and parentheses while parsing them.
+// A number directly followed by a coin multiplies it, so "0.5 btc" is the value of half a bitcoin.
+type calcParser struct {
+	chatID int64
+	tokens []calcToken
+	pos    int
+	coins  []price.PriceInfo
+}
+
+// CommandCalc evaluates an expression such as "0.5 btc + 10 eth in usd" or "sol/eth" with the cached prices
+func CommandCalc(chatID int64, argument string) (string, error) {
+	log.Debugf("processing command /calc with argument :%s", argument)
+
+	tokens, err := tokenizeCalc(argument)
+	if err != nil {
+		return "", err
+	}
+	if len(tokens) == 0 {
+		return "", ErrCalcSyntax
+	}
+
+	// A trailing "in <currency>" converts the result
+	var target string
+	if n := len(tokens); n >= 3 && isCalcConversion(tokens[n-2:]) {
+		target = tokens[n-1].text
+		tokens = tokens[:n-2]
+	}
+
+	p := &calcParser{chatID: chatID, tokens: tokens}
+	result, err := p.parseExpression()
+	if err == nil && p.pos < len(p.tokens) {
+		err = ErrCalcSyntax
+	}
+	// Only plain numbers and dollar values make sense, "btc eth" would be in square dollars
+	if err == nil && result.usd != 0 && result.usd != 1 {
+		err = &calcError{msgID: "calc_invalid_units"}
+	}
+
+	var targetCoin *price.PriceInfo
+	if err == nil && target != "" {
+		var conversion calcValue
+		conversion, err = p.resolve(target)
+		if err == nil && result.usd != 1 {
+			err = &calcError{msgID: "calc_not_a_value", arg: strings.ToUpper(NormalizeAlias(target))}
+		}
+		if err == nil {
+			result = calcValue{value: result.value / conversion.value}
+			if NormalizeAlias(target) != "usd" {
+				targetCoin = &p.coins[len(p.coins)-1]
+			}
+		}
+	}
+
+	if calcErr, ok := err.(*calcError); ok {
+		if calcErr.arg == "" {
+			return translation.Translate(calcErr.msgID), nil
+		}
+		return translation.Translate(calcErr.msgID, helpers.EscapeMarkdownV2(calcErr.arg)), nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var formatted string
+	switch {
+	case targetCoin != nil:
+		formatted = formatCalcNumber(result.value) + " " + helpers.EscapeMarkdownV2(targetCoin.Symbol)
+	case result.usd == 1 || target != "":
+		formatted = formatCalcUSD(result.value)
+	default:
+		formatted = formatCalcNumber(result.value)
+	}
+
+	var prices []string
+	seen := make(map[string]bool)
+	for _, coin := range p.coins {
+		if seen[coin.Symbol] {
+			continue
+		}
+		seen[coin.Symbol] = true
+		prices = append(prices, helpers.EscapeMarkdownV2(coin.Symbol)+" $"+helpers.FormatPriceUS(coin.PriceUSD, true))
+	}
+
+	text := translation.Translate("calc_result", formatCalcExpression(tokens, target), formatted)
+	if len(prices) > 0 {
+		text += translation.Translate("calc_prices", strings.Join(prices, " · "))
+	}
+	return text, nil
+}
+
+func isCalcConversion(tokens []calcToken) bool {
+	keyword := strings.ToLower(tokens[0].text)
+	return tokens[0].kind == calcIdent && (keyword == "in" || keyword == "to") && tokens[1].kind == calcIdent
+}
+
+// parseExpression parses terms joined by + and -
+func (p *calcParser) parseExpression() (calcValue, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return calcValue{}, err
+	}
+
+	for p.peekOperator("+") || p.peekOperator("-") {
+		operator := p.tokens[p.pos].text
+		p.pos++
+		right, err := p.parseTerm()
+		if err != nil {
+			return calcValue{}, err
+		}
+		if left.usd != right.usd {
+			return calcValue{}, &calcError{msgID: "calc_mixed_units", arg: operator}
+		}
+		if operator == "+" {
+			left.value += right.value
+		} else {
+			left.value -= right.value
+		}
+	}
+	return left, nil
+}
+
+// parseTerm parses factors joined by *, / or nothing at all ("0.5 btc")
+func (p *calcParser) parseTerm() (calcValue, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return calcValue{}, err
+	}
+
+	for p.pos < len(p.tokens) {
+		operator := "*"
+		if p.peekOperator("*") || p.peekOperator("/") {
+			operator = p.tokens[p.pos].text
+			p.pos++
+		} else if p.tokens[p.pos].kind == calcNumber ||
+			p.tokens[p.pos].kind == calcOperator && p.tokens[p.pos].text != "(" {
+			break
+		}
+
+		right, err := p.parseFactor()
+		if err != nil {
+			return calcValue{}, err
+		}
+		if operator == "*" {
+			left = calcValue{value: left.value * right.value, usd: left.usd + right.usd}
+		} else {
+			if right.value == 0 {
+				return calcValue{}, &calcError{msgID: "calc_division_by_zero"}
+			}
+			left = calcValue{value: left.value / right.value, usd: left.usd - right.usd}
+		}
+	}
+	return left, nil
+}
+
+// parseFactor parses a number, a coin, a negation or a parenthesized expression
+func (p *calcParser) parseFactor() (calcValue, error) {
+	if p.pos >= len(p.tokens) {
+		return calcValue{}, ErrCalcSyntax
+	}
+
+	token := p.tokens[p.pos]
+	p.pos++
+	switch {
+	case token.kind == calcNumber:
+		return calcValue{value: token.value}, nil
+	case token.kind == calcIdent:
+		return p.resolve(token.text)
+	case token.text == "-":
+		v, err := p.parseFactor()
+		v.value = -v.value
+		return v, err
+	case token.text == "(":
+		v, err := p.parseExpression()
+		if err != nil {
+			return calcValue{}, err
+		}
+		if !p.peekOperator(")") {
+			return calcValue{}, ErrCalcSyntax
+		}
+		p.pos++
+		return v, nil
+	}
+	return calcValue{}, ErrCalcSyntax
+}
+
+func (p *calcParser) peekOperator(operator string) bool {
+	return p.pos < len(p.tokens) && p.tokens[p.pos].kind == calcOperator && p.tokens[p.pos].text == operator
+}
+
+// resolve looks a coin up by the chat's aliases or its exact ID or symbol, "usd" is one dollar.
+// Unlike coin lookups elsewhere names are not matched, so an unsupported currency is never mistaken for a coin.
+func (p *calcParser) resolve(name string) (calcValue, error) {
+	query := NormalizeAlias(name)
+	if query == "usd" {
+		return calcValue{value: 1, usd: 1}, nil
+	}
+
+	coinID := ""
+	if aliased, found, err := database.GetAlias(p.chatID, query); err != nil {
+		log.Error(err)
+	} else if found {
+		coinID = aliased
+	}
+	if coinID == "" {
+		for _, entry := range price.SearchIndex(query, maxIndexResults) {
+			if strings.ToLower(entry.ID) == query || strings.ToLower(entry.Symbol) == query {
+				coinID = entry.ID
+				break
+			}
+		}
+	}
+
+	info, found := price.GetPrice(coinID)
+	if coinID == "" || !found || info.PriceUSD <= 0 {
+		return calcValue{}, &calcError{msgID: "calc_unknown_coin", arg: name}
+	}
+
+	p.coins = append(p.coins, info)
+	return calcValue{value: info.PriceUSD, usd: 1}, nil
+}
+
+// tokenizeCalc splits an expression into numbers (with optional k, m or b suffix), coins and operators
+func tokenizeCalc(text string) ([]calcToken, error) {
+	runes := []rune(strings.ToLower(text))
+	var tokens []calcToken
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case strings.ContainsRune("+-*/()", r):
+			tokens = append(tokens, calcToken{kind: calcOperator, text: string(r)})
+			i++
+		case unicode.IsDigit(r) || r == '.':
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			value, err := strconv.ParseFloat(string(runes[start:i]), 64)
+			if err != nil {
+				return nil, ErrCalcSyntax
+			}
+			// "10k" is ten thousand, while "10kas" is ten KAS
+			if i < len(runes) && strings.ContainsRune("kmb", runes[i]) && (i+1 == len(runes) || !isCalcIdentRune(runes[i+1])) {
+				value *= map[rune]float64{'k': 1e3, 'm': 1e6, 'b': 1e9}[runes[i]]
+				i++
+			}
+			tokens = append(tokens, calcToken{kind: calcNumber, text: string(runes[start:i]), value: value})
+		case r == '$' || unicode.IsLetter(r):
+			start := i
+			i++
+			for i < len(runes) && (isCalcIdentRune(runes[i]) || runes[i] == '-') {
+				i++
+			}
+			// Dashes belong to coin IDs such as btc-bitcoin, anywhere else they are a minus
+			word := string(runes[start:i])
+			if dash := strings.IndexRune(word, '-'); dash >= 0 {
+				if _, found := price.GetPrice(strings.TrimPrefix(word, "$")); !found {
+					word = word[:dash]
+					i = start + len([]rune(word))
+				}
+			}
+			if word == "$" {
+				return nil, ErrCalcSyntax
+			}
+			tokens = append(tokens, calcToken{kind: calcIdent, text: word})
+		default:
+			return nil, ErrCalcSyntax
+		}
+	}
+	return tokens, nil
+}
+
+func isCalcIdentRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// formatCalcExpression echoes the parsed expression with upper case symbols
+func formatCalcExpression(tokens []calcToken, target string) string {
+	var parts []string
+	for _, token := range tokens {
+		switch token.kind {
+		case calcIdent:
+			parts = append(parts, strings.ToUpper(strings.TrimPrefix(token.text, "$")))
+		default:
+			parts = append(parts, token.text)
+		}
+	}
+	expression := strings.Join(parts, " ")
+	expression = strings.ReplaceAll(strings.ReplaceAll(expression, "( ", "("), " )", ")")
+	if target != "" {
+		expression += " in " + strings.ToUpper(strings.TrimPrefix(target, "$"))
+	}
+	return expression
+}
+
+func formatCalcUSD(value float64) string {
+	if value < 0 {
+		return "\\-$" + formatCalcNumber(-value)
+	}
+	return "$" + formatCalcNumber(value)
+}
+
+func formatCalcNumber(value float64) string {
+	if value == 0 || math.IsInf(value, 0) || math.IsNaN(value) {
+		return helpers.EscapeMarkdownV2(strconv.FormatFloat(value, 'f', -1, 64))
+	}
+	if value < 0 {
+		return "\\-" + helpers.FormatPriceUS(-value, true)
+	}
+	return helpers.FormatPriceUS(value, true)
+}
@@ -13,13 +13,17 @@ import (
 	"time"
 )
 
-var ValidTimeRanges = map[string]time.Time{
-	"4h":  time.Now().Add(-4 * time.Hour).UTC(),
-	"12h": time.Now().Add(-12 * time.Hour).UTC(),
-	"24h": time.Now().Add(-24 * time.Hour).UTC(),
-	"7d":  time.Now().Add(-24 * 7 * time.Hour).UTC(),
+// ValidTimeRanges maps the supported chart ranges to how far back they reach
+var ValidTimeRanges = map[string]time.Duration{
+	"4h":  4 * time.Hour,
+	"12h": 12 * time.Hour,
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
 }
 
+const defaultTimeRange = "7d"
+
 func init() {
 	darkGrayBlueSeriesColors := []drawing.Color{
 		{R: 0, G: 122, B: 255, A: 255},
@@ -45,16 +49,27 @@ func init() {
 }
 
 // CommandChart generates the chart and returns the file path.
-func CommandChart(argument, timeRange string) ([]byte, string, error) {
+func CommandChart(chatID int64, argument, timeRange string) ([]byte, string, error) {
 	log.Printf("processing command /c with argument :%s", argument)
-	t := getTimeRange(timeRange)
-	i := getInterval(timeRange)
-	if cachedItem, found := cacheGet(fmt.Sprintf("%s-%s", argument, t.String())); found {
-		log.Printf("returning cached result for %s", argument)
+
+	c, err := ResolveCoin(chatID, argument)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "unable to find coin by query")
+	}
+
+	return CoinChart(c, timeRange)
+}
+
+// CoinChart renders the price chart of an already resolved coin
+func CoinChart(c *coinpaprika.Coin, timeRange string) ([]byte, string, error) {
+	timeRange = NormalizeTimeRange(timeRange)
+	cacheKey := chartCacheKey(*c.ID, "chart", timeRange)
+	if cachedItem, found := cacheGet(cacheKey); found {
+		log.Printf("returning cached result for %s", *c.ID)
 		return cachedItem.ChartData, cachedItem.Caption, nil
 	}
 
-	c, tickers, _ := GetHistoricalTickersByQuery(argument, t, i)
+	_, tickers, _ := GetHistoricalTickers(c, getTimeRange(timeRange), getInterval(timeRange))
 
 	if len(tickers) <= 0 {
 		return nil, translation.Translate(
@@ -67,24 +82,35 @@ func CommandChart(argument, timeRange string) ([]byte, string, error) {
 		return nil, "", err
 	}
 
-	cacheSet(argument, chartData, *c.Name, 5*time.Minute)
-
-	return chartData, translation.Translate(
+	caption := translation.Translate(
 		"Coin chart details",
-		*c.Symbol, *c.ID), nil
+		*c.Symbol, *c.ID)
+	cacheSet(cacheKey, chartData, caption, 5*time.Minute)
+
+	return chartData, caption, nil
 }
 
-func CommandChartWithTicker(argument, timeRange string) ([]byte, string, error) {
+func CommandChartWithTicker(chatID int64, argument, timeRange string) ([]byte, string, error) {
 	log.Printf("processing command ticker with argument :%s", argument)
-	t := getTimeRange(timeRange)
-	i := getInterval(timeRange)
-	cacheKey := fmt.Sprintf("%s-%s-%s", argument, "ticker", t.String())
+
+	c, err := ResolveCoin(chatID, argument)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "unable to find coin by query")
+	}
+
+	return CoinChartWithTicker(c, timeRange)
+}
+
+// CoinChartWithTicker renders the overview chart and ticker details caption for an already resolved coin
+func CoinChartWithTicker(c *coinpaprika.Coin, timeRange string) ([]byte, string, error) {
+	timeRange = NormalizeTimeRange(timeRange)
+	cacheKey := chartCacheKey(*c.ID, "ticker", timeRange)
 	if cachedItem, found := cacheGet(cacheKey); found {
-		log.Printf("returning cached result for %s", argument)
+		log.Printf("returning cached result for %s", *c.ID)
 		return cachedItem.ChartData, cachedItem.Caption, nil
 	}
 
-	c, tickers, err := GetHistoricalTickersByQuery(argument, t, i)
+	_, tickers, err := GetHistoricalTickers(c, getTimeRange(timeRange), getInterval(timeRange))
 	if err != nil {
 		return nil, "", err
 	}
@@ -160,6 +186,31 @@ func renderChart(c *coinpaprika.Coin, tickers []*coinpaprika.TickerHistorical, t
 		return nil, errors.New("not enough data points for rendering chart")
 	}
 
+	titleKey := ""
+	switch timeRange {
+	case "4h":
+		titleKey = "price chart 4h"
+	case "12h":
+		titleKey = "price chart 12h"
+	case "24h":
+		titleKey = "price chart 24h"
+	case "30d":
+		titleKey = "price chart 30d"
+	default:
+		titleKey = "price chart 7d"
+	}
+
+	intraday := timeRange == "4h" || timeRange == "12h" || timeRange == "24h"
+
+	return renderTimeSeries(translation.Translate(titleKey, *c.Name, *c.Symbol), times, prices, intraday, formatChartPrice)
+}
+
+// renderTimeSeries renders a single filled line series with time labels on the X-axis.
+func renderTimeSeries(title string, times []*time.Time, prices []*float64, intraday bool, formatter chart.ValueFormatter) ([]byte, error) {
+	if len(prices) < 2 || len(times) != len(prices) {
+		return nil, errors.New("not enough data points for rendering chart")
+	}
+
 	priceValues := [][]float64{{}}
 	for _, price := range prices {
 		priceValues[0] = append(priceValues[0], *price)
@@ -170,7 +221,7 @@ func renderChart(c *coinpaprika.Coin, tickers []*coinpaprika.TickerHistorical, t
 	var lastLabel string
 	for _, t := range times {
 		var currentLabel string
-		if timeRange == "4h" || timeRange == "12h" || timeRange == "24h" {
+		if intraday {
 			currentLabel = (*t).Format("15:04") // Show time for shorter ranges
 		} else {
 			currentLabel = (*t).Format("02-Jan") // Show date for weekly range
@@ -198,18 +249,6 @@ func renderChart(c *coinpaprika.Coin, tickers []*coinpaprika.TickerHistorical, t
 	minValue := minPrice - padding
 	maxValue := maxPrice + padding
 
-	titleKey := ""
-	switch timeRange {
-	case "4h":
-		titleKey = "price chart 4h"
-	case "12h":
-		titleKey = "price chart 12h"
-	case "24h":
-		titleKey = "price chart 24h"
-	default:
-		titleKey = "price chart 7d"
-	}
-
 	p, err := chart.LineRender(
 		priceValues,
 		chart.TitleTextOptionFunc("CoinPaprika"),
@@ -222,13 +261,11 @@ func renderChart(c *coinpaprika.Coin, tickers []*coinpaprika.TickerHistorical, t
 			opt.SymbolShow = BoolPtr(true)
 			opt.Opacity = 35
 			opt.Title = chart.TitleOption{
-				Text: translation.Translate(titleKey, *c.Name, *c.Symbol),
+				Text: title,
 				Left: "center",
 				Top:  "20px",
 			}
-			opt.ValueFormatter = func(v float64) string {
-				return helpers.FormatPriceUS(v, false)
-			}
+			opt.ValueFormatter = formatter
 			opt.XAxis = chart.XAxisOption{
 				Data:        xLabels,
 				BoundaryGap: BoolPtr(false),
@@ -262,6 +299,10 @@ func renderChart(c *coinpaprika.Coin, tickers []*coinpaprika.TickerHistorical, t
 	return buf, nil
 }
 
+func formatChartPrice(v float64) string {
+	return helpers.FormatPriceUS(v, false)
+}
+
 func getMinMax(prices []*float64) (min, max float64) {
 	if len(prices) == 0 {
 		return 0, 1
@@ -292,13 +333,31 @@ func BoolPtr(b bool) *bool {
 	return &b
 }
 
-func getTimeRange(timeRange string) time.Time {
-	if t, valid := ValidTimeRanges[timeRange]; valid {
-		return t
+// NormalizeTimeRange returns the time range if it is supported and the default range otherwise
+func NormalizeTimeRange(timeRange string) string {
+	if _, valid := ValidTimeRanges[timeRange]; valid {
+		return timeRange
 	}
-	log.Printf("Invalid time range: %s. Defaulting to 7d.", timeRange)
+	if timeRange != "" {
+		log.Printf("Invalid time range: %s. Defaulting to %s.", timeRange, defaultTimeRange)
+	}
+	return defaultTimeRange
+}
+
+// ForgetCoinCharts drops the cached charts of a coin for the time range so they are rendered again
+func ForgetCoinCharts(coinID, timeRange string) {
+	timeRange = NormalizeTimeRange(timeRange)
+	cacheDelete(chartCacheKey(coinID, "chart", timeRange))
+	cacheDelete(chartCacheKey(coinID, "ticker", timeRange))
+}
 
-	return ValidTimeRanges["7d"]
+func chartCacheKey(coinID, kind, timeRange string) string {
+	return fmt.Sprintf("%s-%s-%s", coinID, kind, timeRange)
+}
+
+// getTimeRange returns the start of the time range, computed on every call so it does not go stale
+func getTimeRange(timeRange string) time.Time {
+	return time.Now().Add(-ValidTimeRanges[NormalizeTimeRange(timeRange)]).UTC()
 }
 
 func getInterval(timeRange string) string {
@@ -313,6 +372,8 @@ func getInterval(timeRange string) string {
 		interval = "2h"
 	case "7d":
 		interval = "3h"
+	case "30d":
+		interval = "6h"
 	default:
 		interval = "3h"
 	}
@@ -0,0 +1,114 @@
+package commands
+
+import (
+	"github.com/coinpaprika/coinpaprika-api-go-client/v2/coinpaprika"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"regexp"
+	"strings"
+)
+
+var (
+	evmAddressRegex    = regexp.MustCompile(`^0x[a-fA-F0-9]{40}$`)
+	tronAddressRegex   = regexp.MustCompile(`^T[1-9A-HJ-NP-Za-km-z]{33}$`)
+	solanaAddressRegex = regexp.MustCompile(`^[1-9A-HJ-NP-Za-km-z]{32,44}$`)
+	tonAddressRegex    = regexp.MustCompile(`^(EQ|UQ)[A-Za-z0-9_-]{46}$`)
+)
+
+// evmPlatforms are tried in order when an EVM address is pasted without a platform
+var evmPlatforms = []string{
+	"eth-ethereum",
+	"bnb-binance-coin",
+	"matic-polygon",
+	"arb-arbitrum",
+	"op-optimism",
+	"avax-avalanche",
+	"ftm-fantom",
+}
+
+// platformAliases maps the short platform names accepted by /ca to coinpaprika platform IDs
+var platformAliases = map[string]string{
+	"eth":       "eth-ethereum",
+	"ethereum":  "eth-ethereum",
+	"bsc":       "bnb-binance-coin",
+	"bnb":       "bnb-binance-coin",
+	"polygon":   "matic-polygon",
+	"matic":     "matic-polygon",
+	"arb":       "arb-arbitrum",
+	"arbitrum":  "arb-arbitrum",
+	"op":        "op-optimism",
+	"optimism":  "op-optimism",
+	"avax":      "avax-avalanche",
+	"avalanche": "avax-avalanche",
+	"ftm":       "ftm-fantom",
+	"fantom":    "ftm-fantom",
+	"sol":       "sol-solana",
+	"solana":    "sol-solana",
+	"trx":       "trx-tron",
+	"tron":      "trx-tron",
+	"ton":       "ton-toncoin",
+}
+
+// ParseContractAddress checks whether the text is a contract address and returns the platforms it may belong to
+func ParseContractAddress(text string) (string, []string, bool) {
+	address := strings.TrimSpace(text)
+
+	switch {
+	case evmAddressRegex.MatchString(address):
+		return address, evmPlatforms, true
+	case tonAddressRegex.MatchString(address):
+		return address, []string{"ton-toncoin"}, true
+	case tronAddressRegex.MatchString(address):
+		return address, []string{"trx-tron", "sol-solana"}, true
+	case solanaAddressRegex.MatchString(address):
+		return address, []string{"sol-solana"}, true
+	}
+
+	return "", nil, false
+}
+
+// DetectContractAddress checks whether a message pasted into the chat is a contract address.
+// Only formats which ordinary words cannot match are detected, Solana addresses need /ca.
+func DetectContractAddress(text string) (string, []string, bool) {
+	address := strings.TrimSpace(text)
+
+	switch {
+	case evmAddressRegex.MatchString(address):
+		return address, evmPlatforms, true
+	case tonAddressRegex.MatchString(address):
+		return address, []string{"ton-toncoin"}, true
+	case tronAddressRegex.MatchString(address):
+		return address, []string{"trx-tron"}, true
+	}
+
+	return "", nil, false
+}
+
+// IsContractAddress reports whether the text is a pasted contract address
+func IsContractAddress(text string) bool {
+	_, _, ok := DetectContractAddress(text)
+	return ok
+}
+
+// ResolvePlatform maps a user supplied platform name to a coinpaprika platform ID
+func ResolvePlatform(platform string) string {
+	platform = strings.ToLower(strings.TrimSpace(platform))
+	if id, found := platformAliases[platform]; found {
+		return id
+	}
+	return platform
+}
+
+// SearchContract resolves a contract address to a coin, trying the platforms in order
+func SearchContract(address string, platforms []string) (*coinpaprika.Coin, error) {
+	for _, platform := range platforms {
+		c, err := GetCoinByContract(platform, address)
+		if err != nil {
+			log.Debugf("no coin for contract %s on %s: %v", address, platform, err)
+			continue
+		}
+		return c, nil
+	}
+
+	return nil, errors.Errorf("no coin found for contract address: %s", address)
+}
@@ -0,0 +1,209 @@
+package commands
+
+import (
+	"coinpaprika-telegram-bot/internal/chart"
+	"coinpaprika-telegram-bot/lib/helpers"
+	"coinpaprika-telegram-bot/lib/translation"
+	"fmt"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	defaultCorrelationDays = 90
+	maxCorrelationDays     = 365
+	// MaxCorrelationCoins keeps the cells of the matrix large enough for their values
+	MaxCorrelationCoins = 8
+)
+
+// ErrInvalidCorrelation is returned when the arguments of /corr cannot be parsed
+var ErrInvalidCorrelation = errors.New("invalid correlation arguments")
+
+// ErrNoCorrelationData is returned when fewer than two coins have enough historical prices
+var ErrNoCorrelationData = errors.New("not enough historical data for correlation")
+
+// correlationPair is the correlation of the daily returns of two coins
+type correlationPair struct {
+	A, B  string
+	Value float64
+}
+
+// CommandCorrelation renders a matrix of the pairwise correlations of daily returns, e.g. "btc eth sol bnb 90d"
+func CommandCorrelation(chatID int64, argument string) ([]byte, string, error) {
+	log.Debugf("processing command /corr with argument :%s", argument)
+
+	var queries []string
+	timeRange := ""
+	for _, field := range strings.Fields(strings.ToLower(argument)) {
+		if timeRange == "" && dayRangeRegex.MatchString(field) {
+			timeRange = field
+			continue
+		}
+		queries = append(queries, field)
+	}
+	if len(queries) < 2 || len(queries) > MaxCorrelationCoins {
+		return nil, "", ErrInvalidCorrelation
+	}
+
+	days, err := parseDayRange(timeRange, defaultCorrelationDays, maxCorrelationDays)
+	if err != nil {
+		return nil, "", ErrInvalidCorrelation
+	}
+
+	end := time.Now().UTC()
+	start := end.AddDate(0, 0, -days)
+
+	var symbols, missing []string
+	var returns [][]dailyReturn
+	seen := make(map[string]bool)
+	for _, query := range queries {
+		c, err := ResolveCoin(chatID, query)
+		if err != nil {
+			return nil, "", errors.Wrap(err, "command /corr")
+		}
+		if seen[*c.ID] {
+			continue
+		}
+		seen[*c.ID] = true
+
+		tickers, err := GetHistoricalTickersRange(c, start, end, "1d")
+		if err != nil {
+			log.Error(err)
+		}
+		coinReturns := dailyReturns(tickers)
+		if len(coinReturns) < minStatsReturns {
+			missing = append(missing, helpers.EscapeMarkdownV2(*c.Symbol))
+			continue
+		}
+		symbols = append(symbols, *c.Symbol)
+		returns = append(returns, coinReturns)
+	}
+	if len(symbols) < 2 {
+		return nil, "", ErrNoCorrelationData
+	}
+
+	matrix := make([][]float64, len(symbols))
+	var pairs []correlationPair
+	for i := range symbols {
+		matrix[i] = make([]float64, len(symbols))
+		for j := range symbols {
+			switch {
+			case i == j:
+				matrix[i][j] = 1
+			case j < i:
+				matrix[i][j] = matrix[j][i]
+			default:
+				value, ok := correlation(returns[i], returns[j])
+				if !ok {
+					matrix[i][j] = chart.GetNullValue()
+					continue
+				}
+				matrix[i][j] = value
+				pairs = append(pairs, correlationPair{A: symbols[i], B: symbols[j], Value: value})
+			}
+		}
+	}
+
+	chartData, err := renderCorrelationMatrix(symbols, matrix, days)
+	if err != nil {
+		return nil, "", err
+	}
+
+	caption := translation.Translate("corr_caption", days)
+	if len(pairs) > 1 {
+		highest, lowest := pairs[0], pairs[0]
+		for _, pair := range pairs {
+			if pair.Value > highest.Value {
+				highest = pair
+			}
+			if pair.Value < lowest.Value {
+				lowest = pair
+			}
+		}
+		caption += translation.Translate("corr_extremes", formatCorrelationPair(highest), formatCorrelationPair(lowest))
+	}
+	if len(missing) > 0 {
+		caption += translation.Translate("corr_missing", strings.Join(missing, ", "))
+	}
+
+	return chartData, caption, nil
+}
+
+// correlation is the Pearson correlation of two return series on the days both have a return
+func correlation(a, b []dailyReturn) (float64, bool) {
+	xs, ys := alignReturns(a, b)
+	if len(xs) < minStatsReturns {
+		return 0, false
+	}
+
+	meanX, deviationX := meanDeviation(xs)
+	meanY, deviationY := meanDeviation(ys)
+	if deviationX == 0 || deviationY == 0 {
+		return 0, false
+	}
+
+	var covariance float64
+	for i := range xs {
+		covariance += (xs[i] - meanX) * (ys[i] - meanY)
+	}
+	covariance /= float64(len(xs) - 1)
+	return math.Max(-1, math.Min(1, covariance/(deviationX*deviationY))), true
+}
+
+func formatCorrelationPair(pair correlationPair) string {
+	return helpers.EscapeMarkdownV2(fmt.Sprintf("%s/%s %.2f", pair.A, pair.B, pair.Value))
+}
+
+func renderCorrelationMatrix(symbols []string, matrix [][]float64, days int) ([]byte, error) {
+	seriesList := make(chart.SeriesList, len(symbols))
+	for i, symbol := range symbols {
+		seriesList[i] = chart.NewSeriesFromValues(matrix[i], chart.ChartTypeHeatmap)
+		seriesList[i].Name = symbol
+	}
+
+	// Cells stay close to square whatever the number of coins
+	size := 160 + 100*len(symbols)
+	p, err := chart.Render(
+		chart.ChartOption{SeriesList: seriesList},
+		chart.ThemeOptionFunc(chart.ThemeDark),
+		chart.WidthOptionFunc(size+60),
+		chart.HeightOptionFunc(size),
+		func(opt *chart.ChartOption) {
+			opt.BackgroundColor = chart.Color{R: 55, G: 55, B: 55, A: 255}
+			opt.Title = chart.TitleOption{
+				Text: translation.Translate("corr_chart_title", days),
+				Left: "center",
+				Top:  "20px",
+			}
+			opt.XAxis = chart.XAxisOption{
+				Data: symbols,
+			}
+			// Negative correlations are blue, unrelated returns gray and moving together red
+			opt.VisualMap = chart.VisualMapOption{
+				Min: -1,
+				Max: 1,
+				Colors: []chart.Color{
+					{R: 0x54, G: 0x70, B: 0xc6, A: 255},
+					{R: 90, G: 90, B: 90, A: 255},
+					{R: 0xee, G: 0x66, B: 0x66, A: 255},
+				},
+			}
+			opt.ValueFormatter = func(f float64) string {
+				return fmt.Sprintf("%.2f", f)
+			}
+		},
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to render correlation chart")
+	}
+
+	buf, err := p.Bytes()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate chart bytes")
+	}
+
+	return buf, nil
+}
@@ -0,0 +1,227 @@
+package commands
+
+import (
+	"coinpaprika-telegram-bot/internal/chart"
+	"coinpaprika-telegram-bot/lib/helpers"
+	"coinpaprika-telegram-bot/lib/translation"
+	"github.com/coinpaprika/coinpaprika-api-go-client/v2/coinpaprika"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"strings"
+	"time"
+)
+
+const (
+	defaultDCADays = 365
+	maxDCADays     = 5 * 365
+	dcaChartLabels = 12
+)
+
+// dcaFrequencies maps the /dca frequencies to the step between two purchases
+var dcaFrequencies = map[string]func(time.Time) time.Time{
+	"daily":    func(t time.Time) time.Time { return t.AddDate(0, 0, 1) },
+	"weekly":   func(t time.Time) time.Time { return t.AddDate(0, 0, 7) },
+	"biweekly": func(t time.Time) time.Time { return t.AddDate(0, 0, 14) },
+	"monthly":  func(t time.Time) time.Time { return t.AddDate(0, 1, 0) },
+}
+
+// ErrInvalidDCA is returned when the arguments of /dca cannot be parsed
+var ErrInvalidDCA = errors.New("invalid dca arguments")
+
+// ErrNoDCAData is returned when there are not enough historical prices to simulate
+var ErrNoDCAData = errors.New("not enough historical data for dca")
+
+// dcaPoint is the state of a simulated plan at the close of a day
+type dcaPoint struct {
+	Time     time.Time
+	Invested float64
+	Value    float64
+}
+
+// dcaResult is the outcome of a simulated dollar-cost averaging plan
+type dcaResult struct {
+	Points   []dcaPoint
+	Buys     int
+	Invested float64
+	Coins    float64
+}
+
+// CommandDCA simulates buying a fixed USD amount of a coin at a regular frequency, e.g. "btc 100 weekly 2y",
+// and returns the equity curve chart with a summary caption
+func CommandDCA(chatID int64, argument string) ([]byte, string, error) {
+	log.Debugf("processing command /dca with argument :%s", argument)
+
+	fields := strings.Fields(strings.ToLower(argument))
+	if len(fields) < 3 || len(fields) > 4 {
+		return nil, "", ErrInvalidDCA
+	}
+
+	amount, ok := parseUSDAmount(fields[1])
+	step, known := dcaFrequencies[fields[2]]
+	if !ok || !known {
+		return nil, "", ErrInvalidDCA
+	}
+
+	timeRange := ""
+	if len(fields) == 4 {
+		timeRange = fields[3]
+	}
+	days, err := parseDayRange(timeRange, defaultDCADays, maxDCADays)
+	if err != nil {
+		return nil, "", ErrInvalidDCA
+	}
+
+	c, err := ResolveCoin(chatID, fields[0])
+	if err != nil {
+		return nil, "", errors.Wrap(err, "command /dca")
+	}
+
+	end := time.Now().UTC()
+	tickers, err := GetHistoricalTickersRange(c, end.AddDate(0, 0, -days), end, "1d")
+	if err != nil {
+		log.Error(err)
+		return nil, "", ErrNoDCAData
+	}
+
+	result := simulateDCA(tickers, amount, step)
+	if len(result.Points) < 2 || result.Coins == 0 {
+		return nil, "", ErrNoDCAData
+	}
+
+	current := currentPrice(c)
+	if current <= 0 {
+		current = result.Points[len(result.Points)-1].Value / result.Coins
+	}
+	value := result.Coins * current
+
+	chartData, err := renderDCAChart(*c.Symbol, result.Points, days)
+	if err != nil {
+		return nil, "", err
+	}
+
+	caption := translation.Translate(
+		"dca_caption",
+		helpers.EscapeMarkdownV2(*c.Name),
+		helpers.EscapeMarkdownV2(*c.Symbol),
+		helpers.FormatPriceUS(amount, true),
+		translation.Translate("dca_frequency_"+fields[2]),
+		result.Buys,
+		helpers.EscapeMarkdownV2(result.Points[0].Time.Format("Jan 2, 2006")),
+		helpers.FormatPriceUS(result.Invested, true),
+		helpers.FormatPriceUS(value, true),
+		formatSignedUSD(value-result.Invested),
+		formatSignedPercent(percentOf(value-result.Invested, result.Invested)),
+		FormatAmount(roundAmount(result.Coins)),
+		helpers.EscapeMarkdownV2(*c.Symbol),
+		helpers.FormatPriceUS(result.Invested/result.Coins, true),
+		helpers.FormatPriceUS(current, true),
+	)
+
+	return chartData, caption, nil
+}
+
+// simulateDCA buys amount USD at the first daily ticker on or after every scheduled date
+func simulateDCA(tickers []*coinpaprika.TickerHistorical, amount float64, step func(time.Time) time.Time) dcaResult {
+	var result dcaResult
+	var nextBuy time.Time
+
+	for _, ticker := range tickers {
+		if ticker.Timestamp == nil || ticker.Price == nil || *ticker.Price <= 0 {
+			continue
+		}
+		if nextBuy.IsZero() {
+			nextBuy = *ticker.Timestamp
+		}
+
+		if !ticker.Timestamp.Before(nextBuy) {
+			result.Coins += amount / *ticker.Price
+			result.Invested += amount
+			result.Buys++
+			// Purchases missed during gaps in the data are skipped, not bought late in a batch
+			for !nextBuy.After(*ticker.Timestamp) {
+				nextBuy = step(nextBuy)
+			}
+		}
+
+		result.Points = append(result.Points, dcaPoint{
+			Time:     *ticker.Timestamp,
+			Invested: result.Invested,
+			Value:    result.Coins * *ticker.Price,
+		})
+	}
+	return result
+}
+
+func renderDCAChart(symbol string, points []dcaPoint, days int) ([]byte, error) {
+	layout := "02-Jan"
+	if days > 90 {
+		layout = "Jan 06"
+	}
+
+	// The axis draws every label which is not "-", so only about a dozen dates are kept
+	step := (len(points) + dcaChartLabels - 1) / dcaChartLabels
+
+	var xLabels []string
+	values := [][]float64{{}, {}}
+	for i, point := range points {
+		if i%step == 0 {
+			xLabels = append(xLabels, point.Time.Format(layout))
+		} else {
+			xLabels = append(xLabels, "-")
+		}
+		values[0] = append(values[0], point.Value)
+		values[1] = append(values[1], point.Invested)
+	}
+
+	minValue := 0.0
+	p, err := chart.LineRender(
+		values,
+		chart.TitleTextOptionFunc("CoinPaprika"),
+		chart.ThemeOptionFunc(chart.ThemeDark),
+		chart.WidthOptionFunc(1200),
+		chart.LegendLabelsOptionFunc([]string{
+			translation.Translate("dca_chart_value"),
+			translation.Translate("dca_chart_invested"),
+		}, "center"),
+		func(opt *chart.ChartOption) {
+			opt.BackgroundColor = chart.Color{R: 55, G: 55, B: 55, A: 255}
+			opt.FillArea = true
+			opt.SymbolShow = BoolPtr(false)
+			opt.Opacity = 35
+			opt.Title = chart.TitleOption{
+				Text: translation.Translate("dca_chart_title", symbol),
+				Left: "center",
+				Top:  "20px",
+			}
+			opt.Legend.Top = "50"
+			opt.ValueFormatter = helpers.FormatCompactUS
+			opt.XAxis = chart.XAxisOption{
+				Data:        xLabels,
+				BoundaryGap: BoolPtr(false),
+				FontSize:    12,
+				FontColor:   chart.Color{R: 200, G: 200, B: 200, A: 255},
+				Show:        BoolPtr(true),
+			}
+			opt.YAxisOptions = []chart.YAxisOption{
+				{
+					Min:           &minValue,
+					FontSize:      12,
+					FontColor:     chart.Color{R: 200, G: 200, B: 200, A: 255},
+					Position:      "left",
+					SplitLineShow: BoolPtr(true),
+					Show:          BoolPtr(true),
+				},
+			}
+		},
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to render dca chart")
+	}
+
+	buf, err := p.Bytes()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate chart bytes")
+	}
+
+	return buf, nil
+}
@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"coinpaprika-telegram-bot/internal/database"
+	"coinpaprika-telegram-bot/internal/price"
+	"coinpaprika-telegram-bot/lib/helpers"
+	"coinpaprika-telegram-bot/lib/translation"
+	log "github.com/sirupsen/logrus"
+	"strings"
+)
+
+const (
+	// digestMoversRank limits the top movers to established coins, small caps would always win
+	digestMoversRank  = 100
+	digestMoversLimit = 3
+)
+
+// CommandDigest returns the market digest of a chat: its watchlist table and the top movers of the largest coins
+func CommandDigest(chatID int64) string {
+	var text strings.Builder
+	text.WriteString(translation.Translate("digest_header"))
+
+	coinIDs, err := database.GetWatchlist(chatID)
+	if err != nil {
+		log.Error(err)
+	}
+	if len(coinIDs) > 0 {
+		text.WriteString(FormatWatchlist(coinIDs))
+		text.WriteString("\n")
+	}
+
+	gainers, losers := price.GetTopMovers(digestMoversRank, digestMoversLimit)
+	if len(gainers) > 0 {
+		text.WriteString(translation.Translate("digest_gainers_header", digestMoversRank))
+		for _, p := range gainers {
+			text.WriteString(formatMover(p))
+		}
+	}
+	if len(losers) > 0 {
+		text.WriteString(translation.Translate("digest_losers_header", digestMoversRank))
+		for _, p := range losers {
+			text.WriteString(formatMover(p))
+		}
+	}
+
+	return text.String()
+}
+
+func formatMover(p price.PriceInfo) string {
+	return translation.Translate(
+		"digest_mover_item",
+		helpers.EscapeMarkdownV2(p.Symbol),
+		helpers.FormatPriceUS(p.PriceUSD, true),
+		formatSignedPercent(p.PriceChange24h),
+	)
+}
@@ -0,0 +1,153 @@
+package commands
+
+import (
+	"coinpaprika-telegram-bot/lib/helpers"
+	"coinpaprika-telegram-bot/lib/translation"
+	"github.com/coinpaprika/coinpaprika-api-go-client/v2/coinpaprika"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	maxUpcomingEvents = 5
+	maxRecentEvents   = 3
+	recentEventsRange = 30 * 24 * time.Hour
+)
+
+// CommandEvents lists upcoming and recent events for a coin
+func CommandEvents(chatID int64, argument string) (string, error) {
+	log.Debugf("processing command /events with argument :%s", argument)
+
+	c, err := ResolveCoin(chatID, strings.TrimSpace(argument))
+	if err != nil {
+		return "", errors.Wrap(err, "command /events")
+	}
+
+	events, err := GetEventsByCoinID(*c.ID)
+	if err != nil {
+		return "", errors.Wrap(err, "command /events")
+	}
+
+	today := truncateDay(time.Now().UTC())
+	var upcoming, recent []*coinpaprika.Event
+	for _, event := range events {
+		start, end, ok := eventDates(event)
+		if !ok {
+			continue
+		}
+		if !end.Before(today) {
+			upcoming = append(upcoming, event)
+		} else if start.After(today.Add(-recentEventsRange)) {
+			recent = append(recent, event)
+		}
+	}
+
+	if len(upcoming) == 0 && len(recent) == 0 {
+		return translation.Translate(
+			"no_events_found",
+			helpers.EscapeMarkdownV2(*c.Name), *c.ID), nil
+	}
+
+	sort.Slice(upcoming, func(i, j int) bool { return *upcoming[i].Date < *upcoming[j].Date })
+	sort.Slice(recent, func(i, j int) bool { return *recent[i].Date > *recent[j].Date })
+
+	var text strings.Builder
+	text.WriteString(translation.Translate(
+		"events_header",
+		helpers.EscapeMarkdownV2(*c.Name), *c.ID, helpers.EscapeMarkdownV2(*c.Symbol)))
+
+	if len(upcoming) > 0 {
+		text.WriteString(translation.Translate("events_upcoming_header"))
+		for i, event := range upcoming {
+			if i >= maxUpcomingEvents {
+				break
+			}
+			text.WriteString(FormatEvent(event))
+		}
+	}
+
+	if len(recent) > 0 {
+		text.WriteString(translation.Translate("events_recent_header"))
+		for i, event := range recent {
+			if i >= maxRecentEvents {
+				break
+			}
+			text.WriteString(FormatEvent(event))
+		}
+	}
+
+	text.WriteString(translation.Translate("events_footer", *c.ID))
+
+	return text.String(), nil
+}
+
+// FormatEvent formats a single event as a MarkdownV2 list item
+func FormatEvent(event *coinpaprika.Event) string {
+	start, _, _ := eventDates(event)
+
+	name := helpers.EscapeMarkdownV2(stringValue(event.Name))
+	if event.Link != nil && *event.Link != "" {
+		name = "[" + name + "](" + helpers.EscapeMarkdownV2Link(*event.Link) + ")"
+	}
+
+	kind := ""
+	if event.IsConference != nil && *event.IsConference {
+		kind = translation.Translate("event_conference_tag")
+	}
+
+	return translation.Translate(
+		"event_item_format",
+		helpers.EscapeMarkdownV2(start.Format("Jan 2, 2006")), name, kind)
+}
+
+// EventsOn returns the events taking place on the given day
+func EventsOn(events []*coinpaprika.Event, day time.Time) []*coinpaprika.Event {
+	day = truncateDay(day.UTC())
+
+	var result []*coinpaprika.Event
+	for _, event := range events {
+		start, end, ok := eventDates(event)
+		if !ok {
+			continue
+		}
+		if !day.Before(truncateDay(start)) && !day.After(truncateDay(end)) {
+			result = append(result, event)
+		}
+	}
+	return result
+}
+
+// eventDates returns the start and end of an event, the end defaults to the start for single day events
+func eventDates(event *coinpaprika.Event) (time.Time, time.Time, bool) {
+	if event == nil || event.Date == nil {
+		return time.Time{}, time.Time{}, false
+	}
+
+	start, err := time.Parse(time.RFC3339, *event.Date)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+
+	end := start
+	if event.DateTo != nil && *event.DateTo != "" {
+		if t, err := time.Parse(time.RFC3339, *event.DateTo); err == nil && t.After(start) {
+			end = t
+		}
+	}
+
+	return start, end, true
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func stringValue(v *string) string {
+	if v == nil {
+		return ""
+	}
+	return *v
+}
@@ -0,0 +1,173 @@
+package commands
+
+import (
+	"bytes"
+	"coinpaprika-telegram-bot/lib/helpers"
+	"coinpaprika-telegram-bot/lib/translation"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/coinpaprika/coinpaprika-api-go-client/v2/coinpaprika"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultExportDays = 30
+	maxExportDays     = 365
+
+	ExportCSV  = "csv"
+	ExportJSON = "json"
+)
+
+// ErrInvalidExport is returned when the arguments of /export cannot be parsed
+var ErrInvalidExport = errors.New("invalid export arguments")
+
+// ErrNoExportData is returned when the API has no historical tickers for the requested range
+var ErrNoExportData = errors.New("no historical data to export")
+
+// ExportFile is a rendered /export document
+type ExportFile struct {
+	Name    string
+	Data    []byte
+	Caption string
+}
+
+// exportRow is a single historical ticker of an export, also the JSON layout of a data point
+type exportRow struct {
+	Timestamp time.Time `json:"timestamp"`
+	Price     float64   `json:"price"`
+	Volume24h float64   `json:"volume_24h"`
+	MarketCap float64   `json:"market_cap"`
+}
+
+// CommandExport exports the historical price, volume and market cap of a coin as a CSV or JSON document.
+// The range and format may be given in any order after the coin: "btc 90d json".
+func CommandExport(chatID int64, argument string) (*ExportFile, error) {
+	log.Debugf("processing command /export with argument :%s", argument)
+
+	fields := strings.Fields(strings.ToLower(argument))
+	if len(fields) == 0 || len(fields) > 3 {
+		return nil, ErrInvalidExport
+	}
+
+	format, timeRange := ExportCSV, ""
+	for _, field := range fields[1:] {
+		switch field {
+		case ExportCSV, ExportJSON:
+			format = field
+		default:
+			if timeRange != "" {
+				return nil, ErrInvalidExport
+			}
+			timeRange = field
+		}
+	}
+
+	days, err := parseDayRange(timeRange, defaultExportDays, maxExportDays)
+	if err != nil {
+		return nil, ErrInvalidExport
+	}
+
+	c, err := ResolveCoin(chatID, fields[0])
+	if err != nil {
+		return nil, errors.Wrap(err, "command /export")
+	}
+
+	end := time.Now().UTC()
+	start := end.AddDate(0, 0, -days)
+	interval := exportInterval(days)
+
+	tickers, err := GetHistoricalTickersRange(c, start, end, interval)
+	if err != nil {
+		// Plans without access to the range are rejected by the API, which reads the same as no data to the user
+		log.Error(err)
+		return nil, ErrNoExportData
+	}
+
+	var rows []exportRow
+	for _, ticker := range tickers {
+		if ticker.Timestamp == nil || ticker.Price == nil {
+			continue
+		}
+		rows = append(rows, exportRow{
+			Timestamp: ticker.Timestamp.UTC(),
+			Price:     *ticker.Price,
+			Volume24h: floatValue(ticker.Volume24h),
+			MarketCap: floatValue(ticker.MarketCap),
+		})
+	}
+	if len(rows) == 0 {
+		return nil, ErrNoExportData
+	}
+
+	var data []byte
+	if format == ExportJSON {
+		data, err = exportJSON(c, interval, rows)
+	} else {
+		data, err = exportCSV(rows)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to encode export")
+	}
+
+	return &ExportFile{
+		Name: fmt.Sprintf("%s_%dd_%s.%s", *c.ID, days, interval, format),
+		Data: data,
+		Caption: translation.Translate(
+			"export_caption",
+			helpers.EscapeMarkdownV2(*c.Name),
+			helpers.EscapeMarkdownV2(*c.Symbol),
+			len(rows),
+			interval,
+			helpers.EscapeMarkdownV2(rows[0].Timestamp.Format("2006-01-02 15:04")),
+			helpers.EscapeMarkdownV2(rows[len(rows)-1].Timestamp.Format("2006-01-02 15:04")),
+		),
+	}, nil
+}
+
+// exportInterval picks the finest interval which keeps a range within a few thousand rows
+func exportInterval(days int) string {
+	switch {
+	case days <= 2:
+		return "5m"
+	case days <= 30:
+		return "1h"
+	}
+	return "1d"
+}
+
+func exportCSV(rows []exportRow) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"timestamp", "price", "volume_24h", "market_cap"})
+	for _, row := range rows {
+		w.Write([]string{
+			row.Timestamp.Format(time.RFC3339),
+			strconv.FormatFloat(row.Price, 'f', -1, 64),
+			strconv.FormatFloat(row.Volume24h, 'f', -1, 64),
+			strconv.FormatFloat(row.MarketCap, 'f', -1, 64),
+		})
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+func exportJSON(c *coinpaprika.Coin, interval string, rows []exportRow) ([]byte, error) {
+	return json.MarshalIndent(struct {
+		CoinID   string      `json:"coin_id"`
+		Symbol   string      `json:"symbol"`
+		Quote    string      `json:"quote"`
+		Interval string      `json:"interval"`
+		Data     []exportRow `json:"data"`
+	}{
+		CoinID:   *c.ID,
+		Symbol:   *c.Symbol,
+		Quote:    "USD",
+		Interval: interval,
+		Data:     rows,
+	}, "", "  ")
+}
@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"coinpaprika-telegram-bot/internal/database"
+	"coinpaprika-telegram-bot/lib/helpers"
+	"coinpaprika-telegram-bot/lib/translation"
+	"fmt"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"math"
+	"time"
+)
+
+const (
+	globalSnapshotInterval  = 1 * time.Hour
+	globalSnapshotRetention = 30 * 24 * time.Hour
+	globalSparklineRange    = 7 * 24 * time.Hour
+)
+
+// CommandGlobal returns the global market overview with an optional market cap sparkline
+func CommandGlobal() ([]byte, string, error) {
+	log.Debug("processing command /global")
+
+	if cachedItem, found := cacheGet("global"); found {
+		log.Debug("returning cached result for /global")
+		return cachedItem.ChartData, cachedItem.Caption, nil
+	}
+
+	stats, err := GetGlobalStats()
+	if err != nil {
+		return nil, "", errors.Wrap(err, "command /global")
+	}
+
+	athDate := "N/A"
+	if stats.MarketCapATHDate != nil {
+		athDate = stats.MarketCapATHDate.Format("Jan 2, 2006")
+	}
+
+	caption := translation.Translate(
+		"Global market overview",
+		helpers.FormatPriceRoundedUS(math.Round(floatValue(stats.MarketCapUSD))),
+		helpers.EscapeMarkdownV2(fmt.Sprintf("%.2f", floatValue(stats.MarketCapChange24h))),
+		helpers.FormatPriceRoundedUS(math.Round(floatValue(stats.Volume24hUSD))),
+		helpers.EscapeMarkdownV2(fmt.Sprintf("%.2f", floatValue(stats.Volume24hChange24h))),
+		helpers.EscapeMarkdownV2(fmt.Sprintf("%.2f", floatValue(stats.BitcoinDominancePercentage))),
+		helpers.FormatSupplyUS(int64Value(stats.CryptocurrenciesNumber)),
+		helpers.FormatPriceRoundedUS(math.Round(floatValue(stats.MarketCapATHValue))),
+		helpers.EscapeMarkdownV2(athDate),
+	)
+
+	chartData, err := renderGlobalSparkline()
+	if err != nil {
+		log.Debugf("skipping global sparkline: %v", err)
+		chartData = nil
+	}
+
+	cacheSet("global", chartData, caption, 5*time.Minute)
+
+	return chartData, caption, nil
+}
+
+// StartGlobalSnapshotService records global market snapshots used by the /global sparkline
+func StartGlobalSnapshotService() {
+	go func() {
+		for {
+			recordGlobalSnapshot()
+			time.Sleep(globalSnapshotInterval)
+		}
+	}()
+	log.Println("🚀 Global snapshot service started.")
+}
+
+func recordGlobalSnapshot() {
+	stats, err := GetGlobalStats()
+	if err != nil {
+		log.Errorf("❌ Failed to fetch global market data: %v", err)
+		return
+	}
+
+	err = database.InsertGlobalSnapshot(
+		floatValue(stats.MarketCapUSD),
+		floatValue(stats.Volume24hUSD),
+		floatValue(stats.BitcoinDominancePercentage),
+	)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	if err := database.DeleteGlobalSnapshotsBefore(time.Now().Add(-globalSnapshotRetention)); err != nil {
+		log.Error(err)
+	}
+}
+
+func renderGlobalSparkline() ([]byte, error) {
+	snapshots, err := database.GetGlobalSnapshotsSince(time.Now().Add(-globalSparklineRange))
+	if err != nil {
+		return nil, err
+	}
+
+	var times []*time.Time
+	var values []*float64
+	for i := range snapshots {
+		times = append(times, &snapshots[i].CreatedAt)
+		values = append(values, &snapshots[i].MarketCap)
+	}
+
+	return renderTimeSeries(translation.Translate("global market cap chart"), times, values, false, helpers.FormatCompactUS)
+}
+
+func floatValue(v *float64) float64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+func int64Value(v *int64) int64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
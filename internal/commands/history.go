@@ -0,0 +1,166 @@
+package commands
+
+import (
+	"coinpaprika-telegram-bot/internal/price"
+	"coinpaprika-telegram-bot/lib/helpers"
+	"coinpaprika-telegram-bot/lib/translation"
+	"fmt"
+	"github.com/coinpaprika/coinpaprika-api-go-client/v2/coinpaprika"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// historicalDateLayouts are the absolute date formats accepted by /h
+var historicalDateLayouts = []string{"2006-01-02", "2006/01/02", "02.01.2006"}
+
+// relativeDateRegex matches relative dates such as "3y ago", "6 months ago" or "2w"
+var relativeDateRegex = regexp.MustCompile(`^(\d+)\s*(d|days?|w|weeks?|m|months?|y|years?)(\s+ago)?$`)
+
+// dayRangeRegex matches the day ranges of commands such as /pf chart, /export and /corr, like "90d", "12w", "6m" or "1y"
+var dayRangeRegex = regexp.MustCompile(`^(\d+)(d|w|m|y)$`)
+
+// ErrInvalidDayRange is returned when a range of days cannot be parsed
+var ErrInvalidDayRange = errors.New("invalid range of days")
+
+// ErrInvalidDate is returned when the date of /h cannot be parsed or lies in the future
+var ErrInvalidDate = errors.New("invalid historical date")
+
+// CommandHistory returns the price, market cap and volume of a coin on a past date
+func CommandHistory(chatID int64, argument string) (string, error) {
+	log.Debugf("processing command /h with argument :%s", argument)
+
+	fields := strings.Fields(argument)
+	if len(fields) < 2 {
+		return "", ErrInvalidDate
+	}
+
+	day, err := ParseHistoricalDate(strings.Join(fields[1:], " "), time.Now().UTC())
+	if err != nil {
+		return "", err
+	}
+
+	c, err := ResolveCoin(chatID, fields[0])
+	if err != nil {
+		return "", errors.Wrap(err, "command /h")
+	}
+
+	historical, found := tickerOnDay(c, day)
+	if !found {
+		return translation.Translate(
+			"history_unavailable",
+			helpers.EscapeMarkdownV2(*c.Name), helpers.EscapeMarkdownV2(day.Format("Jan 2, 2006"))), nil
+	}
+
+	current := currentPrice(c)
+	change := "N/A"
+	if current > 0 && *historical.Price > 0 {
+		change = fmt.Sprintf("%+.2f%%", (current-*historical.Price) / *historical.Price * 100)
+	}
+
+	return translation.Translate(
+		"Coin historical details",
+		helpers.EscapeMarkdownV2(*c.Name),
+		helpers.EscapeMarkdownV2(*c.Symbol),
+		helpers.EscapeMarkdownV2(day.Format("Jan 2, 2006")),
+		helpers.FormatPriceUS(*historical.Price, true),
+		helpers.FormatPriceRoundedUS(math.Round(floatValue(historical.MarketCap))),
+		helpers.FormatPriceRoundedUS(math.Round(floatValue(historical.Volume24h))),
+		helpers.FormatPriceUS(current, true),
+		helpers.EscapeMarkdownV2(change),
+		*c.ID,
+	), nil
+}
+
+// tickerOnDay returns the daily ticker of a coin on the given day, false when the coin was not listed yet
+func tickerOnDay(c *coinpaprika.Coin, day time.Time) (*coinpaprika.TickerHistorical, bool) {
+	_, tickers, err := GetHistoricalTickers(c, day, "1d")
+	// The endpoint starts at the coin's first ticker for dates before it was listed
+	if err != nil || len(tickers) == 0 || tickers[0].Price == nil ||
+		(tickers[0].Timestamp != nil && tickers[0].Timestamp.Sub(day) >= 24*time.Hour) {
+		return nil, false
+	}
+	return tickers[0], true
+}
+
+// currentPrice returns the live USD price of a coin from the price cache, asking the API on a miss, 0 when unknown
+func currentPrice(c *coinpaprika.Coin) float64 {
+	if p, found := price.GetPrice(*c.ID); found {
+		return p.PriceUSD
+	}
+	if _, ticker, _ := GetTicker(c); ticker != nil && ticker.Quotes["USD"].Price != nil {
+		return *ticker.Quotes["USD"].Price
+	}
+	return 0
+}
+
+// ParseHistoricalDate parses an absolute (2021-11-10) or relative (3y ago) date into the start of that UTC day
+func ParseHistoricalDate(text string, now time.Time) (time.Time, error) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	today := truncateDay(now.UTC())
+
+	var day time.Time
+	if matches := relativeDateRegex.FindStringSubmatch(text); matches != nil {
+		n, err := strconv.Atoi(matches[1])
+		if err != nil {
+			return time.Time{}, ErrInvalidDate
+		}
+
+		switch matches[2][0] {
+		case 'd':
+			day = today.AddDate(0, 0, -n)
+		case 'w':
+			day = today.AddDate(0, 0, -7*n)
+		case 'm':
+			day = today.AddDate(0, -n, 0)
+		case 'y':
+			day = today.AddDate(-n, 0, 0)
+		}
+	} else {
+		for _, layout := range historicalDateLayouts {
+			if t, err := time.Parse(layout, text); err == nil {
+				day = t
+				break
+			}
+		}
+	}
+
+	if day.IsZero() || day.After(today) {
+		return time.Time{}, ErrInvalidDate
+	}
+	return day, nil
+}
+
+// parseDayRange parses ranges such as "90d", "12w", "6m" or "1y" into days, capped at maxDays
+func parseDayRange(text string, defaultDays, maxDays int) (int, error) {
+	if text == "" {
+		return defaultDays, nil
+	}
+
+	matches := dayRangeRegex.FindStringSubmatch(text)
+	if matches == nil {
+		return 0, ErrInvalidDayRange
+	}
+	n, err := strconv.Atoi(matches[1])
+	if err != nil || n <= 0 {
+		return 0, ErrInvalidDayRange
+	}
+
+	days := n
+	switch matches[2] {
+	case "w":
+		days = 7 * n
+	case "m":
+		days = 30 * n
+	case "y":
+		days = 365 * n
+	}
+	if days > maxDays {
+		days = maxDays
+	}
+	return days, nil
+}
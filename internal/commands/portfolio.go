@@ -0,0 +1,165 @@
+package commands
+
+import (
+	"coinpaprika-telegram-bot/internal/price"
+	"coinpaprika-telegram-bot/internal/types"
+	"coinpaprika-telegram-bot/lib/helpers"
+	"coinpaprika-telegram-bot/lib/translation"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ValuedPosition is a portfolio position valued at the current price
+type ValuedPosition struct {
+	types.Position
+	Name       string
+	Symbol     string
+	Priced     bool // false when the coin is missing from the price cache
+	Price      float64
+	Value      float64
+	Cost       float64
+	PnL        float64
+	PnLPercent float64
+	Allocation float64
+}
+
+// PortfolioValuation is a portfolio valued live from the price cache
+type PortfolioValuation struct {
+	Positions       []ValuedPosition
+	TotalValue      float64
+	TotalCost       float64
+	TotalPnL        float64
+	TotalPnLPercent float64
+}
+
+// ValuePortfolio values positions with the cached prices, largest holdings first
+func ValuePortfolio(positions []types.Position) PortfolioValuation {
+	var v PortfolioValuation
+	for _, position := range positions {
+		vp := ValuedPosition{
+			Position: position,
+			Symbol:   position.CoinID,
+			Cost:     position.Amount * position.CostBasis,
+		}
+
+		if p, found := price.GetPrice(position.CoinID); found {
+			vp.Name, vp.Symbol = p.Name, p.Symbol
+			vp.Priced = true
+			vp.Price = p.PriceUSD
+			vp.Value = position.Amount * p.PriceUSD
+			vp.PnL = vp.Value - vp.Cost
+			vp.PnLPercent = percentOf(vp.PnL, vp.Cost)
+
+			v.TotalValue += vp.Value
+			v.TotalCost += vp.Cost
+		}
+		v.Positions = append(v.Positions, vp)
+	}
+
+	for i := range v.Positions {
+		if v.Positions[i].Priced {
+			v.Positions[i].Allocation = percentOf(v.Positions[i].Value, v.TotalValue)
+		}
+	}
+	sort.SliceStable(v.Positions, func(i, j int) bool { return v.Positions[i].Value > v.Positions[j].Value })
+
+	v.TotalPnL = v.TotalValue - v.TotalCost
+	v.TotalPnLPercent = percentOf(v.TotalPnL, v.TotalCost)
+
+	return v
+}
+
+// FormatPortfolio lists every position with its value, cost basis, PnL and allocation
+func FormatPortfolio(v PortfolioValuation) string {
+	var text strings.Builder
+	text.WriteString(translation.Translate("pf_header"))
+
+	for _, vp := range v.Positions {
+		if !vp.Priced {
+			text.WriteString(translation.Translate(
+				"pf_position_unpriced",
+				helpers.EscapeMarkdownV2(vp.Symbol), FormatAmount(vp.Amount)))
+			continue
+		}
+
+		text.WriteString(translation.Translate(
+			"pf_position_format",
+			helpers.EscapeMarkdownV2(vp.Symbol),
+			FormatAmount(vp.Amount),
+			helpers.FormatPriceUS(vp.Price, true),
+			helpers.FormatPriceUS(vp.Value, true),
+			formatPercent(vp.Allocation),
+			helpers.FormatPriceUS(vp.CostBasis, true),
+			formatSignedUSD(vp.PnL),
+			formatSignedPercent(vp.PnLPercent),
+		))
+	}
+
+	text.WriteString(translation.Translate(
+		"pf_total_format",
+		helpers.FormatPriceUS(v.TotalValue, true),
+		helpers.FormatPriceUS(v.TotalCost, true),
+		formatSignedUSD(v.TotalPnL),
+		formatSignedPercent(v.TotalPnLPercent),
+	))
+
+	return text.String()
+}
+
+// FormatPortfolioSummary lists allocations and returns only, so holdings can be shared in a group without amounts
+func FormatPortfolioSummary(owner string, v PortfolioValuation) string {
+	var text strings.Builder
+	text.WriteString(translation.Translate("pf_share_header", helpers.EscapeMarkdownV2(owner)))
+
+	for _, vp := range v.Positions {
+		if !vp.Priced {
+			continue
+		}
+		text.WriteString(translation.Translate(
+			"pf_share_item",
+			helpers.EscapeMarkdownV2(vp.Symbol),
+			formatPercent(vp.Allocation),
+			formatSignedPercent(vp.PnLPercent),
+		))
+	}
+
+	text.WriteString(translation.Translate("pf_share_total", formatSignedPercent(v.TotalPnLPercent)))
+
+	return text.String()
+}
+
+// FormatAmount formats a coin amount without trailing zeros, escaped for MarkdownV2
+func FormatAmount(amount float64) string {
+	return helpers.EscapeMarkdownV2(strconv.FormatFloat(amount, 'f', -1, 64))
+}
+
+func formatSignedUSD(value float64) string {
+	// Sub-cent differences would otherwise be printed with 8 decimals
+	if math.Abs(value) < 0.005 {
+		return "$0"
+	}
+
+	sign := "\\+"
+	if value < 0 {
+		sign = "\\-"
+	}
+	return sign + "$" + helpers.FormatPriceUS(math.Abs(value), true)
+}
+
+func formatSignedPercent(value float64) string {
+	return helpers.EscapeMarkdownV2(fmt.Sprintf("%+.2f", value))
+}
+
+func formatPercent(value float64) string {
+	return helpers.EscapeMarkdownV2(fmt.Sprintf("%.1f", value))
+}
+
+func percentOf(part, total float64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return part / total * 100
+}
@@ -0,0 +1,226 @@
+package commands
+
+import (
+	"coinpaprika-telegram-bot/internal/chart"
+	"coinpaprika-telegram-bot/internal/database"
+	"coinpaprika-telegram-bot/internal/price"
+	"coinpaprika-telegram-bot/lib/helpers"
+	"coinpaprika-telegram-bot/lib/translation"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"sort"
+	"time"
+)
+
+const (
+	portfolioSnapshotInterval  = 1 * time.Hour
+	portfolioSnapshotRetention = 366 * 24 * time.Hour
+	snapshotDateLayout         = "2006-01-02"
+
+	defaultPortfolioChartDays = 90
+	maxPortfolioChartDays     = 365
+	// portfolioChartCoins is the number of largest holdings drawn separately, the rest is stacked as "Other"
+	portfolioChartCoins = 5
+)
+
+// ErrNotEnoughPortfolioHistory is returned when fewer than two daily snapshots are available
+var ErrNotEnoughPortfolioHistory = errors.New("not enough portfolio history")
+
+// StartPortfolioSnapshotService records the daily value of every portfolio used by /pf chart
+func StartPortfolioSnapshotService() {
+	go func() {
+		for {
+			recordPortfolioSnapshots()
+			time.Sleep(portfolioSnapshotInterval)
+		}
+	}()
+	log.Println("🚀 Portfolio snapshot service started.")
+}
+
+// recordPortfolioSnapshots replaces today's snapshot of every user, so the last run of a day wins
+func recordPortfolioSnapshots() {
+	userIDs, err := database.GetPortfolioUserIDs()
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	today := time.Now().UTC().Format(snapshotDateLayout)
+	for _, userID := range userIDs {
+		positions, err := database.GetPortfolio(userID)
+		if err != nil {
+			log.Error(err)
+			continue
+		}
+
+		values := make(map[string]float64)
+		for _, vp := range ValuePortfolio(positions).Positions {
+			if vp.Priced {
+				values[vp.CoinID] = vp.Value
+			}
+		}
+		// Skip until the price cache is warm instead of storing an empty day
+		if len(values) == 0 {
+			continue
+		}
+
+		if err := database.SavePortfolioSnapshot(userID, today, values); err != nil {
+			log.Error(err)
+		}
+	}
+
+	retention := time.Now().UTC().Add(-portfolioSnapshotRetention).Format(snapshotDateLayout)
+	if err := database.DeletePortfolioSnapshotsBefore(retention); err != nil {
+		log.Error(err)
+	}
+}
+
+// ParsePortfolioRange parses the range of /pf chart into a number of days
+func ParsePortfolioRange(text string) (int, error) {
+	return parseDayRange(text, defaultPortfolioChartDays, maxPortfolioChartDays)
+}
+
+// CommandPortfolioChart renders the daily portfolio value of a user with the allocation as stacked areas
+func CommandPortfolioChart(userID int64, days int) ([]byte, string, error) {
+	since := time.Now().UTC().AddDate(0, 0, -days).Format(snapshotDateLayout)
+	snapshots, err := database.GetPortfolioSnapshotsSince(userID, since)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var dates []string
+	dateIndex := make(map[string]int)
+	latest := make(map[string]float64)
+	for _, snapshot := range snapshots {
+		if _, found := dateIndex[snapshot.Date]; !found {
+			dateIndex[snapshot.Date] = len(dates)
+			dates = append(dates, snapshot.Date)
+			latest = make(map[string]float64)
+		}
+		latest[snapshot.CoinID] = snapshot.Value
+	}
+	if len(dates) < 2 {
+		return nil, "", ErrNotEnoughPortfolioHistory
+	}
+
+	// The largest holdings of the last day get their own area
+	var coinIDs []string
+	for coinID := range latest {
+		coinIDs = append(coinIDs, coinID)
+	}
+	sort.Slice(coinIDs, func(i, j int) bool { return latest[coinIDs[i]] > latest[coinIDs[j]] })
+	if len(coinIDs) > portfolioChartCoins {
+		coinIDs = coinIDs[:portfolioChartCoins]
+	}
+	seriesIndex := make(map[string]int)
+	var labels []string
+	for i, coinID := range coinIDs {
+		seriesIndex[coinID] = i
+		labels = append(labels, portfolioChartLabel(coinID))
+	}
+	other := -1
+	for _, snapshot := range snapshots {
+		if _, found := seriesIndex[snapshot.CoinID]; !found {
+			labels = append(labels, translation.Translate("pf_chart_other"))
+			other = len(labels) - 1
+			break
+		}
+	}
+
+	values := make([][]float64, len(labels))
+	for i := range values {
+		values[i] = make([]float64, len(dates))
+	}
+	totals := make([]float64, len(dates))
+	for _, snapshot := range snapshots {
+		i, found := seriesIndex[snapshot.CoinID]
+		if !found {
+			i = other
+		}
+		values[i][dateIndex[snapshot.Date]] += snapshot.Value
+		totals[dateIndex[snapshot.Date]] += snapshot.Value
+	}
+
+	chartData, err := renderPortfolioChart(labels, values, dates, days)
+	if err != nil {
+		return nil, "", err
+	}
+
+	first, last := totals[0], totals[len(totals)-1]
+	caption := translation.Translate(
+		"pf_chart_caption",
+		len(dates),
+		helpers.FormatPriceUS(last, true),
+		formatSignedPercent(percentOf(last-first, first)),
+	)
+
+	return chartData, caption, nil
+}
+
+func portfolioChartLabel(coinID string) string {
+	if p, found := price.GetPrice(coinID); found {
+		return p.Symbol
+	}
+	return coinID
+}
+
+func renderPortfolioChart(labels []string, values [][]float64, dates []string, days int) ([]byte, error) {
+	var xLabels []string
+	for _, date := range dates {
+		if t, err := time.Parse(snapshotDateLayout, date); err == nil {
+			xLabels = append(xLabels, t.Format("02-Jan"))
+		} else {
+			xLabels = append(xLabels, date)
+		}
+	}
+
+	minValue := 0.0
+	p, err := chart.LineRender(
+		values,
+		chart.TitleTextOptionFunc("CoinPaprika"),
+		chart.ThemeOptionFunc(chart.ThemeDark),
+		chart.WidthOptionFunc(1200),
+		chart.LegendLabelsOptionFunc(labels, "center"),
+		func(opt *chart.ChartOption) {
+			opt.BackgroundColor = chart.Color{R: 55, G: 55, B: 55, A: 255}
+			opt.FillArea = true
+			opt.StackArea = true
+			opt.SymbolShow = BoolPtr(false)
+			opt.Opacity = 150
+			opt.Title = chart.TitleOption{
+				Text: translation.Translate("pf_chart_title", days),
+				Left: "center",
+				Top:  "20px",
+			}
+			opt.Legend.Top = "50"
+			opt.ValueFormatter = helpers.FormatCompactUS
+			opt.XAxis = chart.XAxisOption{
+				Data:        xLabels,
+				BoundaryGap: BoolPtr(false),
+				FontSize:    12,
+				FontColor:   chart.Color{R: 200, G: 200, B: 200, A: 255},
+				Show:        BoolPtr(true),
+			}
+			opt.YAxisOptions = []chart.YAxisOption{
+				{
+					Min:           &minValue,
+					FontSize:      12,
+					FontColor:     chart.Color{R: 200, G: 200, B: 200, A: 255},
+					Position:      "left",
+					SplitLineShow: BoolPtr(true),
+					Show:          BoolPtr(true),
+				},
+			}
+		},
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to render portfolio chart")
+	}
+
+	buf, err := p.Bytes()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate chart bytes")
+	}
+
+	return buf, nil
+}
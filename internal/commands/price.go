@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"coinpaprika-telegram-bot/internal/price"
 	"coinpaprika-telegram-bot/lib/helpers"
 	"coinpaprika-telegram-bot/lib/translation"
 	"github.com/pkg/errors"
@@ -8,10 +9,10 @@ import (
 	"strings"
 )
 
-func CommandPrice(argument string) (string, error) {
+func CommandPrice(chatID int64, argument string) (string, error) {
 	log.Debugf("processing command /p with argument :%s", argument)
 
-	c, ticker, err := GetTickerByQuery(strings.TrimSpace(argument))
+	c, ticker, err := GetTickerByQuery(chatID, strings.TrimSpace(argument))
 	if err != nil {
 		return "", errors.Wrap(err, "command /p")
 	}
@@ -34,3 +35,20 @@ func CommandPrice(argument string) (string, error) {
 		"Coin price details",
 		helpers.EscapeMarkdownV2(*ticker.Name), helpers.FormatPriceUS(*priceUSD, true), helpers.FormatPriceUS(*priceBTC, true), *ticker.Symbol, *ticker.ID), nil
 }
+
+// PriceSummary builds the /p message for a coin from the price cache, without calling the API
+func PriceSummary(coinID string) (string, bool) {
+	p, found := price.GetPrice(coinID)
+	if !found {
+		return "", false
+	}
+
+	btc, found := price.GetPrice("btc-bitcoin")
+	if !found || btc.PriceUSD == 0 {
+		return "", false
+	}
+
+	return translation.Translate(
+		"Coin price details",
+		helpers.EscapeMarkdownV2(p.Name), helpers.FormatPriceUS(p.PriceUSD, true), helpers.FormatPriceUS(p.PriceUSD/btc.PriceUSD, true), helpers.EscapeMarkdownV2(p.Symbol), coinID), true
+}
@@ -2,21 +2,43 @@ package commands
 
 import (
 	"coinpaprika-telegram-bot/config"
+	"coinpaprika-telegram-bot/internal/database"
+	"coinpaprika-telegram-bot/internal/price"
+	"encoding/json"
+	"fmt"
 	"github.com/coinpaprika/coinpaprika-api-go-client/v2/coinpaprika"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
+	"net/http"
+	"net/url"
+	"strings"
 	"time"
 )
 
+const (
+	apiFreeURL = "https://api.coinpaprika.com/v1"
+	apiProURL  = "https://api-pro.coinpaprika.com/v1"
+
+	maxIndexResults = 10
+
+	// historicalPageSize is the row limit of one historical tickers request
+	historicalPageSize = 120
+	// maxHistoricalPages bounds the requests of a single ranged fetch
+	maxHistoricalPages = 50
+)
+
 var paprikaClient *coinpaprika.Client
 
+// contractClient bounds contract lookups, which run on the update loop and may try several platforms
+var contractClient = &http.Client{Timeout: 10 * time.Second}
+
 func init() {
 	paprikaClient = getClient()
 }
 
-// GetTickerByQuery retrieves the ticker for the given query (symbol, name, etc.)
-func GetTickerByQuery(query string) (*coinpaprika.Coin, *coinpaprika.Ticker, error) {
-	currency, err := SearchCoin(query)
+// GetTickerByQuery retrieves the ticker for the given query (symbol, name, etc.), honouring the chat's aliases
+func GetTickerByQuery(chatID int64, query string) (*coinpaprika.Coin, *coinpaprika.Ticker, error) {
+	currency, err := ResolveCoin(chatID, query)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "unable to find coin by query")
 	}
@@ -36,9 +58,9 @@ func GetTicker(currency *coinpaprika.Coin) (*coinpaprika.Coin, *coinpaprika.Tick
 	return currency, ticker, nil
 }
 
-// GetHistoricalTickersByQuery fetches historical tickers for the given query.
-func GetHistoricalTickersByQuery(query string, t time.Time, i string) (*coinpaprika.Coin, []*coinpaprika.TickerHistorical, error) {
-	currency, err := SearchCoin(query)
+// GetHistoricalTickersByQuery fetches historical tickers for the given query, honouring the chat's aliases
+func GetHistoricalTickersByQuery(chatID int64, query string, t time.Time, i string) (*coinpaprika.Coin, []*coinpaprika.TickerHistorical, error) {
+	currency, err := ResolveCoin(chatID, query)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "unable to find coin by query")
 	}
@@ -62,6 +84,36 @@ func GetHistoricalTickers(currency *coinpaprika.Coin, t time.Time, i string) (*c
 	return currency, tickers, nil
 }
 
+// GetHistoricalTickersRange fetches every historical ticker between start and end, paging past the
+// row limit of a single request
+func GetHistoricalTickersRange(currency *coinpaprika.Coin, start, end time.Time, i string) ([]*coinpaprika.TickerHistorical, error) {
+	var result []*coinpaprika.TickerHistorical
+	for page := 0; page < maxHistoricalPages && start.Before(end); page++ {
+		tickers, err := paprikaClient.Tickers.GetHistoricalTickersByID(*currency.ID, &coinpaprika.TickersHistoricalOptions{
+			Quote:    "USD",
+			Limit:    historicalPageSize,
+			Interval: i,
+			Start:    start,
+			End:      end,
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to fetch historical tickers of %s", *currency.ID)
+		}
+
+		for _, ticker := range tickers {
+			if ticker.Timestamp != nil && !ticker.Timestamp.Before(start) {
+				result = append(result, ticker)
+			}
+		}
+
+		if len(tickers) < historicalPageSize || tickers[len(tickers)-1].Timestamp == nil {
+			break
+		}
+		start = tickers[len(tickers)-1].Timestamp.Add(time.Second)
+	}
+	return result, nil
+}
+
 // Get Coin by its ID
 func GetCoinByID(ID string) (*coinpaprika.Coin, error) {
 	result, err := paprikaClient.Coins.GetByID(ID)
@@ -72,8 +124,56 @@ func GetCoinByID(ID string) (*coinpaprika.Coin, error) {
 	return result, nil
 }
 
-// SearchCoin searches for a coin based on the provided query.
+// ResolveCoin resolves a query to a coin, consulting the chat's pinned aliases before searching
+func ResolveCoin(chatID int64, query string) (*coinpaprika.Coin, error) {
+	alias := NormalizeAlias(query)
+	if alias != "" {
+		coinID, found, err := database.GetAlias(chatID, alias)
+		if err != nil {
+			log.Error(err)
+		} else if found {
+			log.Debugf("Alias '%s' of chat %d resolves to %s", alias, chatID, coinID)
+			return GetCoinByID(coinID)
+		}
+	}
+
+	return SearchCoin(query)
+}
+
+// SearchCoinsForChat searches for coins, listing the chat's aliased coin first
+func SearchCoinsForChat(chatID int64, query string) ([]*coinpaprika.Coin, error) {
+	coins, err := SearchCoins(query)
+
+	coinID, found, aliasErr := database.GetAlias(chatID, NormalizeAlias(query))
+	if aliasErr != nil || !found {
+		return coins, err
+	}
+
+	aliased, aliasErr := GetCoinByID(coinID)
+	if aliasErr != nil {
+		return coins, err
+	}
+
+	result := []*coinpaprika.Coin{aliased}
+	for _, c := range coins {
+		if *c.ID != coinID {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+// NormalizeAlias converts a ticker as typed by users ($UNI, Uni) to its stored alias form
+func NormalizeAlias(query string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(query), "$"))
+}
+
+// SearchCoin searches for a coin based on the provided query, using the offline index before the search API.
 func SearchCoin(query string) (*coinpaprika.Coin, error) {
+	if coins := searchIndex(query, 1); len(coins) > 0 {
+		return coins[0], nil
+	}
+
 	searchOpts := &coinpaprika.SearchOptions{
 		Query:      query,
 		Categories: "currencies",
@@ -93,7 +193,13 @@ func SearchCoin(query string) (*coinpaprika.Coin, error) {
 	return result.Currencies[0], nil
 }
 
+// SearchCoins searches for all coins matching the query, using the offline index before the search API.
+// When neither knows the query the closest coins of the index are offered, callers let the user choose among them.
 func SearchCoins(query string) ([]*coinpaprika.Coin, error) {
+	if coins := searchIndex(query, maxIndexResults); len(coins) > 0 {
+		return coins, nil
+	}
+
 	searchOpts := &coinpaprika.SearchOptions{
 		Query:      query,
 		Categories: "currencies",
@@ -105,6 +211,9 @@ func SearchCoins(query string) ([]*coinpaprika.Coin, error) {
 		searchOpts = &coinpaprika.SearchOptions{Query: query, Categories: "currencies"}
 		result, err = paprikaClient.Search.Search(searchOpts)
 		if err != nil || len(result.Currencies) == 0 {
+			if coins := SuggestCoins(query, maxIndexResults); len(coins) > 0 {
+				return coins, nil
+			}
 			return nil, errors.Errorf("invalid coin name, ticker, or symbol: %s", query)
 		}
 	}
@@ -112,6 +221,111 @@ func SearchCoins(query string) ([]*coinpaprika.Coin, error) {
 	return result.Currencies, nil
 }
 
+// SuggestCoins returns the coins of the index with a similar name or symbol, for "did you mean" replies.
+// A query naming a known coin has nothing to suggest.
+func SuggestCoins(query string, limit int) []*coinpaprika.Coin {
+	query = strings.TrimPrefix(strings.TrimSpace(query), "$")
+	if len(price.SearchIndex(query, 1)) > 0 {
+		return nil
+	}
+	return indexCoins(price.SuggestIndex(query, limit))
+}
+
+// searchIndex looks the query up in the index built from the ticker snapshot
+func searchIndex(query string, limit int) []*coinpaprika.Coin {
+	entries := price.SearchIndex(strings.TrimPrefix(query, "$"), limit)
+	if len(entries) == 0 {
+		log.Debugf("No index match for '%s', falling back to the search API", query)
+		return nil
+	}
+	return indexCoins(entries)
+}
+
+// indexCoins converts index entries to the coins of the API client
+func indexCoins(entries []price.CoinEntry) []*coinpaprika.Coin {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	coins := make([]*coinpaprika.Coin, len(entries))
+	for i := range entries {
+		entry := entries[i]
+		coins[i] = &coinpaprika.Coin{
+			ID:     &entry.ID,
+			Name:   &entry.Name,
+			Symbol: &entry.Symbol,
+			Rank:   &entry.Rank,
+		}
+	}
+	return coins
+}
+
+// GetCoinByContract resolves a token contract address on the given platform to its coin
+func GetCoinByContract(platform, address string) (*coinpaprika.Coin, error) {
+	// The contracts endpoint is not covered by the API client, it redirects to the coin's ticker
+	endpoint := fmt.Sprintf("%s/contracts/%s/%s", apiBaseURL(), url.PathEscape(platform), url.PathEscape(address))
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create contract request")
+	}
+	if apiProKey := config.GetString("api_pro_key"); apiProKey != "" {
+		req.Header.Set("Authorization", apiProKey)
+	}
+
+	resp, err := contractClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to fetch contract")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("contract %s not found on %s: status code %d", address, platform, resp.StatusCode)
+	}
+
+	var ticker coinpaprika.Ticker
+	if err := json.NewDecoder(resp.Body).Decode(&ticker); err != nil {
+		return nil, errors.Wrap(err, "unable to parse contract ticker")
+	}
+	if ticker.ID == nil || ticker.Name == nil || ticker.Symbol == nil {
+		return nil, errors.Errorf("invalid ticker for contract %s on %s", address, platform)
+	}
+
+	return &coinpaprika.Coin{
+		ID:     ticker.ID,
+		Name:   ticker.Name,
+		Symbol: ticker.Symbol,
+		Rank:   ticker.Rank,
+	}, nil
+}
+
+// GetEventsByCoinID fetches the events calendar for the given coin
+func GetEventsByCoinID(coinID string) ([]*coinpaprika.Event, error) {
+	events, err := paprikaClient.Coins.GetEventsByCoinID(coinID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to fetch events for coin: %s", coinID)
+	}
+	return events, nil
+}
+
+// GetGlobalStats fetches the global market overview
+func GetGlobalStats() (*coinpaprika.GlobalStats, error) {
+	stats, err := paprikaClient.Global.Get()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to fetch global market data")
+	}
+	if stats == nil {
+		return nil, errors.New("empty global market data")
+	}
+	return stats, nil
+}
+
+func apiBaseURL() string {
+	if config.GetString("api_pro_key") != "" {
+		return apiProURL
+	}
+	return apiFreeURL
+}
+
 func getClient() *coinpaprika.Client {
 	apiProKey := config.GetString("api_pro_key")
 	if apiProKey != "" {
@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"coinpaprika-telegram-bot/lib/helpers"
+	"coinpaprika-telegram-bot/lib/translation"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultROIAmount is the USD amount invested by /roi when none is given
+const defaultROIAmount = 1000
+
+// ErrInvalidROI is returned when the arguments of /roi cannot be parsed
+var ErrInvalidROI = errors.New("invalid roi arguments")
+
+// CommandROI returns what a lump sum invested in a coin on a past date is worth today
+func CommandROI(chatID int64, argument string) (string, error) {
+	log.Debugf("processing command /roi with argument :%s", argument)
+
+	fields := strings.Fields(argument)
+	if len(fields) < 2 {
+		return "", ErrInvalidROI
+	}
+
+	amount := float64(defaultROIAmount)
+	dateFields := fields[1:]
+	if len(fields) > 2 {
+		if value, ok := parseUSDAmount(fields[len(fields)-1]); ok {
+			amount = value
+			dateFields = fields[1 : len(fields)-1]
+		}
+	}
+
+	day, err := ParseHistoricalDate(strings.Join(dateFields, " "), time.Now().UTC())
+	if err != nil {
+		return "", ErrInvalidROI
+	}
+
+	c, err := ResolveCoin(chatID, fields[0])
+	if err != nil {
+		return "", errors.Wrap(err, "command /roi")
+	}
+
+	historical, found := tickerOnDay(c, day)
+	if !found || *historical.Price <= 0 {
+		return translation.Translate(
+			"history_unavailable",
+			helpers.EscapeMarkdownV2(*c.Name), helpers.EscapeMarkdownV2(day.Format("Jan 2, 2006"))), nil
+	}
+
+	current := currentPrice(c)
+	if current <= 0 {
+		return translation.Translate("roi_price_unavailable"), nil
+	}
+
+	coins := amount / *historical.Price
+	value := coins * current
+
+	return translation.Translate(
+		"roi_result",
+		helpers.EscapeMarkdownV2(*c.Name),
+		helpers.EscapeMarkdownV2(*c.Symbol),
+		helpers.EscapeMarkdownV2(day.Format("Jan 2, 2006")),
+		helpers.FormatPriceUS(amount, true),
+		helpers.FormatPriceUS(*historical.Price, true),
+		FormatAmount(roundAmount(coins)),
+		helpers.EscapeMarkdownV2(*c.Symbol),
+		helpers.FormatPriceUS(value, true),
+		helpers.FormatPriceUS(current, true),
+		formatSignedUSD(value-amount),
+		formatSignedPercent(percentOf(value-amount, amount)),
+	), nil
+}
+
+// parseUSDAmount parses a positive dollar amount such as "500", "$500" or "1k"
+func parseUSDAmount(text string) (float64, bool) {
+	text = strings.ToLower(strings.TrimPrefix(text, "$"))
+	multiplier := 1.0
+	if strings.HasSuffix(text, "k") {
+		multiplier, text = 1e3, strings.TrimSuffix(text, "k")
+	}
+
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil || value <= 0 || math.IsInf(value, 0) {
+		return 0, false
+	}
+	return value * multiplier, true
+}
+
+// roundAmount rounds a coin amount to satoshi precision for display
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*1e8) / 1e8
+}
@@ -0,0 +1,371 @@
+package commands
+
+import (
+	"coinpaprika-telegram-bot/internal/chart"
+	"coinpaprika-telegram-bot/lib/helpers"
+	"coinpaprika-telegram-bot/lib/translation"
+	"fmt"
+	"github.com/coinpaprika/coinpaprika-api-go-client/v2/coinpaprika"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultStatsDays = 90
+	maxStatsDays     = 365
+
+	// minStatsReturns is the fewest daily returns the statistics are computed from
+	minStatsReturns = 7
+	// statsBins is the most buckets of the returns histogram, few enough for every bucket to be labelled
+	statsBins = 20
+	// tradingDaysPerYear annualizes daily figures, crypto markets never close
+	tradingDaysPerYear = 365
+
+	bitcoinID = "btc-bitcoin"
+)
+
+// ErrInvalidStats is returned when the arguments of /stats cannot be parsed
+var ErrInvalidStats = errors.New("invalid stats arguments")
+
+// ErrNoStatsData is returned when there are too few historical prices for meaningful statistics
+var ErrNoStatsData = errors.New("not enough historical data for stats")
+
+// dailyReturn is the change of the closing price from the previous day
+type dailyReturn struct {
+	Day   time.Time
+	Value float64
+}
+
+// riskStats are the volatility and risk figures of a coin over a range
+type riskStats struct {
+	Return      float64
+	Volatility  float64
+	MaxDrawdown float64
+	Sharpe      float64
+	Best        dailyReturn
+	Worst       dailyReturn
+	Beta        float64
+	HasBeta     bool
+	Returns     []float64
+}
+
+// CommandStats returns a table of the realized volatility, max drawdown, Sharpe-like ratio, best and worst day
+// and beta against Bitcoin of a coin, e.g. "eth 90d". With "chart" it also renders a histogram of the daily returns.
+func CommandStats(chatID int64, argument string) ([]byte, string, error) {
+	log.Debugf("processing command /stats with argument :%s", argument)
+
+	fields := strings.Fields(strings.ToLower(argument))
+	if len(fields) == 0 || len(fields) > 3 {
+		return nil, "", ErrInvalidStats
+	}
+
+	withChart, timeRange := false, ""
+	for _, field := range fields[1:] {
+		switch {
+		case field == "chart":
+			withChart = true
+		case timeRange == "":
+			timeRange = field
+		default:
+			return nil, "", ErrInvalidStats
+		}
+	}
+
+	days, err := parseDayRange(timeRange, defaultStatsDays, maxStatsDays)
+	if err != nil {
+		return nil, "", ErrInvalidStats
+	}
+
+	c, err := ResolveCoin(chatID, fields[0])
+	if err != nil {
+		return nil, "", errors.Wrap(err, "command /stats")
+	}
+
+	end := time.Now().UTC()
+	start := end.AddDate(0, 0, -days)
+	tickers, err := GetHistoricalTickersRange(c, start, end, "1d")
+	if err != nil {
+		log.Error(err)
+		return nil, "", ErrNoStatsData
+	}
+
+	returns := dailyReturns(tickers)
+	if len(returns) < minStatsReturns {
+		return nil, "", ErrNoStatsData
+	}
+
+	// Beta is left out rather than failing the whole command when Bitcoin prices are unavailable
+	benchmark := returns
+	if *c.ID != bitcoinID {
+		id := bitcoinID
+		btcTickers, err := GetHistoricalTickersRange(&coinpaprika.Coin{ID: &id}, start, end, "1d")
+		if err != nil {
+			log.Error(err)
+		}
+		benchmark = dailyReturns(btcTickers)
+	}
+
+	stats := computeRiskStats(tickers, returns, benchmark)
+	text := formatRiskStats(c, days, stats)
+	if !withChart {
+		return nil, text, nil
+	}
+
+	chartData, err := renderReturnsHistogram(*c.Symbol, stats.Returns)
+	if err != nil {
+		return nil, "", err
+	}
+	return chartData, text, nil
+}
+
+// dailyReturns returns the change between consecutive daily closes, skipping tickers without a price
+func dailyReturns(tickers []*coinpaprika.TickerHistorical) []dailyReturn {
+	var returns []dailyReturn
+	var previous float64
+	for _, ticker := range tickers {
+		if ticker.Timestamp == nil || ticker.Price == nil || *ticker.Price <= 0 {
+			continue
+		}
+		if previous > 0 {
+			returns = append(returns, dailyReturn{
+				Day:   truncateDay(ticker.Timestamp.UTC()),
+				Value: *ticker.Price/previous - 1,
+			})
+		}
+		previous = *ticker.Price
+	}
+	return returns
+}
+
+func computeRiskStats(tickers []*coinpaprika.TickerHistorical, returns, benchmark []dailyReturn) riskStats {
+	stats := riskStats{Best: returns[0], Worst: returns[0]}
+
+	values := make([]float64, len(returns))
+	for i, r := range returns {
+		values[i] = r.Value
+		if r.Value > stats.Best.Value {
+			stats.Best = r
+		}
+		if r.Value < stats.Worst.Value {
+			stats.Worst = r
+		}
+	}
+	stats.Returns = values
+
+	mean, deviation := meanDeviation(values)
+	stats.Volatility = deviation * math.Sqrt(tradingDaysPerYear)
+	if deviation > 0 {
+		stats.Sharpe = mean / deviation * math.Sqrt(tradingDaysPerYear)
+	}
+
+	var first, peak float64
+	for _, ticker := range tickers {
+		if ticker.Price == nil || *ticker.Price <= 0 {
+			continue
+		}
+		p := *ticker.Price
+		if first == 0 {
+			first = p
+		}
+		if p > peak {
+			peak = p
+		}
+		if drawdown := p/peak - 1; drawdown < stats.MaxDrawdown {
+			stats.MaxDrawdown = drawdown
+		}
+		stats.Return = p/first - 1
+	}
+
+	stats.Beta, stats.HasBeta = beta(returns, benchmark)
+	return stats
+}
+
+// beta is the covariance of the coin's daily returns with the benchmark's over the benchmark's variance
+func beta(returns, benchmark []dailyReturn) (float64, bool) {
+	ys, xs := alignReturns(returns, benchmark)
+	if len(xs) < minStatsReturns {
+		return 0, false
+	}
+
+	meanX, deviationX := meanDeviation(xs)
+	meanY, _ := meanDeviation(ys)
+	if deviationX == 0 {
+		return 0, false
+	}
+
+	var covariance float64
+	for i := range xs {
+		covariance += (xs[i] - meanX) * (ys[i] - meanY)
+	}
+	covariance /= float64(len(xs) - 1)
+	return covariance / (deviationX * deviationX), true
+}
+
+// alignReturns returns the values of two return series on the days both have a return
+func alignReturns(a, b []dailyReturn) ([]float64, []float64) {
+	byDay := make(map[time.Time]float64, len(b))
+	for _, r := range b {
+		byDay[r.Day] = r.Value
+	}
+
+	var xs, ys []float64
+	for _, r := range a {
+		if value, found := byDay[r.Day]; found {
+			xs = append(xs, r.Value)
+			ys = append(ys, value)
+		}
+	}
+	return xs, ys
+}
+
+// meanDeviation returns the mean and the sample standard deviation of values
+func meanDeviation(values []float64) (float64, float64) {
+	if len(values) < 2 {
+		return 0, 0
+	}
+
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	var squares float64
+	for _, v := range values {
+		squares += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(squares / float64(len(values)-1))
+}
+
+// formatRiskStats lays the statistics out as a monospace table of labels and right aligned values
+func formatRiskStats(c *coinpaprika.Coin, days int, stats riskStats) string {
+	betaValue := "—"
+	if stats.HasBeta {
+		betaValue = fmt.Sprintf("%.2f", stats.Beta)
+	}
+
+	rows := [][2]string{
+		{translation.Translate("stats_return"), fmt.Sprintf("%+.1f%%", stats.Return*100)},
+		{translation.Translate("stats_volatility"), fmt.Sprintf("%.1f%%", stats.Volatility*100)},
+		{translation.Translate("stats_max_drawdown"), fmt.Sprintf("%.1f%%", stats.MaxDrawdown*100)},
+		{translation.Translate("stats_sharpe"), fmt.Sprintf("%.2f", stats.Sharpe)},
+		{translation.Translate("stats_best_day"), fmt.Sprintf("%+.1f%% %s", stats.Best.Value*100, stats.Best.Day.Format("Jan 02"))},
+		{translation.Translate("stats_worst_day"), fmt.Sprintf("%+.1f%% %s", stats.Worst.Value*100, stats.Worst.Day.Format("Jan 02"))},
+		{translation.Translate("stats_beta"), betaValue},
+	}
+
+	var labelWidth, valueWidth int
+	for _, row := range rows {
+		labelWidth = max(labelWidth, len([]rune(row[0])))
+		valueWidth = max(valueWidth, len([]rune(row[1])))
+	}
+
+	var text strings.Builder
+	text.WriteString(translation.Translate(
+		"stats_header", helpers.EscapeMarkdownV2(*c.Name), helpers.EscapeMarkdownV2(*c.Symbol), days))
+	text.WriteString("```\n")
+	for _, row := range rows {
+		text.WriteString(row[0] + strings.Repeat(" ", labelWidth-len([]rune(row[0]))))
+		text.WriteString(" " + strings.Repeat(" ", valueWidth-len([]rune(row[1]))) + row[1] + "\n")
+	}
+	text.WriteString("```\n")
+	text.WriteString(translation.Translate("stats_footer", len(stats.Returns)))
+	return text.String()
+}
+
+// renderReturnsHistogram draws the distribution of daily returns in percent, losses red and gains green
+func renderReturnsHistogram(symbol string, returns []float64) ([]byte, error) {
+	low, high := returns[0], returns[0]
+	for _, r := range returns {
+		low = math.Min(low, r)
+		high = math.Max(high, r)
+	}
+
+	// Buckets are a round number of percent wide and one of them is centered on zero
+	width := niceStep((high - low) * 100 / statsBins)
+	bucket := func(r float64) int {
+		return int(math.Floor(r*100/width + 0.5))
+	}
+	first := bucket(low)
+	count := bucket(high) - first + 1
+	counts := make([]float64, count)
+	for _, r := range returns {
+		counts[bucket(r)-first]++
+	}
+
+	green := chart.Color{R: 0x91, G: 0xcc, B: 0x75, A: 255}
+	red := chart.Color{R: 0xee, G: 0x66, B: 0x66, A: 255}
+	var xLabels []string
+	data := make([]chart.SeriesData, count)
+	for i := range counts {
+		center := float64(first+i) * width
+		xLabels = append(xLabels, strconv.FormatFloat(math.Round(center*100)/100, 'f', -1, 64)+"%")
+		data[i] = chart.SeriesData{Value: counts[i], Style: chart.Style{FillColor: green}}
+		if center < 0 {
+			data[i].Style.FillColor = red
+		}
+	}
+
+	minValue := 0.0
+	p, err := chart.Render(
+		chart.ChartOption{SeriesList: chart.SeriesList{{Type: chart.ChartTypeBar, Data: data}}},
+		chart.TitleTextOptionFunc("CoinPaprika"),
+		chart.ThemeOptionFunc(chart.ThemeDark),
+		chart.WidthOptionFunc(1200),
+		func(opt *chart.ChartOption) {
+			opt.BackgroundColor = chart.Color{R: 55, G: 55, B: 55, A: 255}
+			opt.Title = chart.TitleOption{
+				Text: translation.Translate("stats_chart_title", symbol),
+				Left: "center",
+				Top:  "20px",
+			}
+			opt.ValueFormatter = func(f float64) string {
+				return strconv.Itoa(int(math.Round(f)))
+			}
+			opt.XAxis = chart.XAxisOption{
+				Data:      xLabels,
+				FontSize:  12,
+				FontColor: chart.Color{R: 200, G: 200, B: 200, A: 255},
+				Show:      BoolPtr(true),
+			}
+			opt.YAxisOptions = []chart.YAxisOption{
+				{
+					Min:           &minValue,
+					FontSize:      12,
+					FontColor:     chart.Color{R: 200, G: 200, B: 200, A: 255},
+					Position:      "left",
+					SplitLineShow: BoolPtr(true),
+					Show:          BoolPtr(true),
+				},
+			}
+		},
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to render stats chart")
+	}
+
+	buf, err := p.Bytes()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate chart bytes")
+	}
+
+	return buf, nil
+}
+
+// niceStep rounds a step up to 1, 2 or 5 times a power of ten
+func niceStep(step float64) float64 {
+	if step <= 0 {
+		return 1
+	}
+	magnitude := math.Pow(10, math.Floor(math.Log10(step)))
+	for _, m := range []float64{1, 2, 5} {
+		if m*magnitude >= step {
+			return m * magnitude
+		}
+	}
+	return 10 * magnitude
+}
@@ -8,10 +8,10 @@ import (
 	"strings"
 )
 
-func CommandSupply(argument string) (string, error) {
+func CommandSupply(chatID int64, argument string) (string, error) {
 	log.Debugf("processing command /s with argument :%s", argument)
 
-	c, ticker, err := GetTickerByQuery(strings.TrimSpace(argument))
+	c, ticker, err := GetTickerByQuery(chatID, strings.TrimSpace(argument))
 	if err != nil {
 		return "", errors.Wrap(err, "command /s")
 	}
@@ -3,15 +3,20 @@ package commands
 import (
 	"coinpaprika-telegram-bot/lib/helpers"
 	"coinpaprika-telegram-bot/lib/translation"
+	"github.com/coinpaprika/coinpaprika-api-go-client/v2/coinpaprika"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"strings"
+	"time"
 )
 
-func CommandVolume(argument string) (string, error) {
+// ErrNoVolumeHistory is returned when there are no historical volumes to average
+var ErrNoVolumeHistory = errors.New("no historical volume")
+
+func CommandVolume(chatID int64, argument string) (string, error) {
 	log.Debugf("processing command /v with argument :%s", argument)
 
-	c, ticker, err := GetTickerByQuery(strings.TrimSpace(argument))
+	c, ticker, err := GetTickerByQuery(chatID, strings.TrimSpace(argument))
 	if err != nil {
 		return "", errors.Wrap(err, "command /v")
 	}
@@ -33,3 +38,25 @@ func CommandVolume(argument string) (string, error) {
 		"Coin volume details",
 		helpers.EscapeMarkdownV2(*ticker.Name), helpers.FormatPriceUS(*volumeUSD, true), *ticker.Symbol, *ticker.ID), nil
 }
+
+// AverageVolume returns the average daily 24h volume of a coin over the last days
+func AverageVolume(c *coinpaprika.Coin, days int) (float64, error) {
+	end := time.Now().UTC()
+	tickers, err := GetHistoricalTickersRange(c, end.AddDate(0, 0, -days), end, "1d")
+	if err != nil {
+		return 0, err
+	}
+
+	var total float64
+	var count int
+	for _, ticker := range tickers {
+		if ticker.Volume24h != nil && *ticker.Volume24h > 0 {
+			total += *ticker.Volume24h
+			count++
+		}
+	}
+	if count == 0 {
+		return 0, ErrNoVolumeHistory
+	}
+	return total / float64(count), nil
+}
@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"coinpaprika-telegram-bot/internal/price"
+	"coinpaprika-telegram-bot/lib/helpers"
+	"coinpaprika-telegram-bot/lib/translation"
+	"fmt"
+	"strings"
+)
+
+// MaxWatchlistCoins keeps the /wl table within a single readable message
+const MaxWatchlistCoins = 30
+
+// FormatWatchlist returns a monospace table with the price, 1h/24h/7d change and market cap of the watched coins
+func FormatWatchlist(coinIDs []string) string {
+	rows := [][]string{{
+		"",
+		translation.Translate("wl_column_price"),
+		"1h",
+		"24h",
+		"7d",
+		translation.Translate("wl_column_mcap"),
+	}}
+
+	var missing []string
+	for _, coinID := range coinIDs {
+		p, found := price.GetPrice(coinID)
+		if !found {
+			missing = append(missing, helpers.EscapeMarkdownV2(coinID))
+			continue
+		}
+
+		rows = append(rows, []string{
+			p.Symbol,
+			"$" + helpers.FormatPriceUS(p.PriceUSD, false),
+			fmt.Sprintf("%+.1f%%", p.PriceChange1h),
+			fmt.Sprintf("%+.1f%%", p.PriceChange24h),
+			fmt.Sprintf("%+.1f%%", p.PriceChange7d),
+			"$" + helpers.FormatCompactUS(p.MarketCap),
+		})
+	}
+
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			if n := len([]rune(cell)); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+
+	var text strings.Builder
+	text.WriteString(translation.Translate("wl_header"))
+	if len(rows) > 1 {
+		text.WriteString("```\n")
+		for _, row := range rows {
+			// The symbol is left aligned, numbers are right aligned so the decimal points line up
+			for i, cell := range row {
+				padding := strings.Repeat(" ", widths[i]-len([]rune(cell)))
+				if i == 0 {
+					text.WriteString(cell + padding)
+				} else {
+					text.WriteString(" " + padding + cell)
+				}
+			}
+			text.WriteString("\n")
+		}
+		text.WriteString("```")
+	}
+
+	if len(missing) > 0 {
+		text.WriteString("\n")
+		text.WriteString(translation.Translate("wl_unpriced", strings.Join(missing, ", ")))
+	}
+
+	return text.String()
+}
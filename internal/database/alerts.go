@@ -2,30 +2,62 @@ package database
 
 import (
 	"coinpaprika-telegram-bot/internal/types"
+	"database/sql"
 	"fmt"
 	"log"
+	"strconv"
+	"time"
 
 	_ "modernc.org/sqlite"
 )
 
+// alertColumns are the columns scanned by scanAlert, in order
+const alertColumns = `id, chat_id, ticker, value, alert_type, current_price, created_at,
+	repeat_interval, hysteresis, armed, last_triggered_at, window_seconds, quote_ticker, expires_at, notify_expiry, user_id`
+
+// alertScanner is implemented by *sql.Row and *sql.Rows
+type alertScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAlert(row alertScanner) (types.Alert, error) {
+	var alert types.Alert
+	var lastTriggeredAt, expiresAt sql.NullTime
+	err := row.Scan(&alert.ID, &alert.ChatID, &alert.Ticker, &alert.Target, &alert.AlertType, &alert.CurrentPrice, &alert.CreatedAt,
+		&alert.RepeatInterval, &alert.Hysteresis, &alert.Armed, &lastTriggeredAt, &alert.Window, &alert.QuoteTicker,
+		&expiresAt, &alert.NotifyExpiry, &alert.UserID)
+	alert.LastTriggeredAt = lastTriggeredAt.Time
+	alert.ExpiresAt = expiresAt.Time
+	return alert, err
+}
+
 // InsertAlert saves an alert to the database
-func InsertAlert(chatID int64, ticker, value, alertType, currentPrice string) error {
+func InsertAlert(alert types.Alert) error {
 	query := `
-	INSERT INTO alerts (chat_id, ticker, value, alert_type, current_price)
-	VALUES (?, ?, ?, ?, ?);`
+	INSERT INTO alerts (chat_id, ticker, value, alert_type, current_price, repeat_interval, hysteresis, window_seconds,
+		quote_ticker, expires_at, notify_expiry, user_id)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`
 
-	_, err := DB.Exec(query, chatID, ticker, value, alertType, currentPrice)
+	value := strconv.FormatFloat(alert.Target, 'f', -1, 64)
+	currentPrice := strconv.FormatFloat(alert.CurrentPrice, 'f', -1, 64)
+	var expiresAt any
+	if !alert.ExpiresAt.IsZero() {
+		expiresAt = alert.ExpiresAt.UTC().Format(sqliteTimeLayout)
+	}
+	_, err := DB.Exec(query, alert.ChatID, alert.Ticker, value, alert.AlertType, currentPrice, alert.RepeatInterval, alert.Hysteresis, alert.Window,
+		alert.QuoteTicker, expiresAt, alert.NotifyExpiry, alert.UserID)
 	if err != nil {
 		return fmt.Errorf("failed to insert alert: %w", err)
 	}
 
-	log.Printf("Alert inserted successfully: ChatID: %d, Ticker: %s, Value: %s, Type: %s, CurrentPrice: %s", chatID, ticker, value, alertType, currentPrice)
+	log.Printf("Alert inserted successfully: ChatID: %d, Ticker: %s, Quote: %s, Value: %s, Type: %s, CurrentPrice: %s, Repeat: %ds",
+		alert.ChatID, alert.Ticker, alert.QuoteTicker, value, alert.AlertType, currentPrice, alert.RepeatInterval)
 	return nil
 }
 
 // GetAllAlerts fetches all alerts from the database
 func GetAllAlerts() ([]types.Alert, error) {
-	query := `SELECT id, chat_id, ticker, value, alert_type, current_price, created_at FROM alerts;`
+	query := `SELECT ` + alertColumns + ` FROM alerts;`
 
 	rows, err := DB.Query(query)
 	if err != nil {
@@ -35,8 +67,8 @@ func GetAllAlerts() ([]types.Alert, error) {
 
 	var alerts []types.Alert
 	for rows.Next() {
-		var alert types.Alert
-		if err := rows.Scan(&alert.ID, &alert.ChatID, &alert.Ticker, &alert.Target, &alert.AlertType, &alert.CurrentPrice, &alert.CreatedAt); err != nil {
+		alert, err := scanAlert(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan row: %w", err)
 		}
 		alerts = append(alerts, alert)
@@ -57,7 +89,7 @@ func DeleteAlert(alertID int64) error {
 
 // GetAlertsByChatID fetches all alerts for a specific chat ID
 func GetAlertsByChatID(chatID int64) ([]types.Alert, error) {
-	query := `SELECT id, ticker, value, alert_type, current_price, created_at FROM alerts WHERE chat_id = ?;`
+	query := `SELECT ` + alertColumns + ` FROM alerts WHERE chat_id = ?;`
 
 	rows, err := DB.Query(query, chatID)
 	if err != nil {
@@ -67,8 +99,8 @@ func GetAlertsByChatID(chatID int64) ([]types.Alert, error) {
 
 	var alerts []types.Alert
 	for rows.Next() {
-		var alert types.Alert
-		if err := rows.Scan(&alert.ID, &alert.Ticker, &alert.Target, &alert.AlertType, &alert.CurrentPrice, &alert.CreatedAt); err != nil {
+		alert, err := scanAlert(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan row: %w", err)
 		}
 		alerts = append(alerts, alert)
@@ -76,3 +108,119 @@ func GetAlertsByChatID(chatID int64) ([]types.Alert, error) {
 
 	return alerts, nil
 }
+
+// GetChatAlert fetches an alert of a chat by its ID, nil when the chat has no such alert
+func GetChatAlert(chatID, alertID int64) (*types.Alert, error) {
+	query := `SELECT ` + alertColumns + ` FROM alerts WHERE chat_id = ? AND id = ?;`
+
+	alert, err := scanAlert(DB.QueryRow(query, chatID, alertID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query alert %d of chat ID %d: %w", alertID, chatID, err)
+	}
+	return &alert, nil
+}
+
+// DeleteChatAlert removes an alert of a chat, reporting whether the chat had it
+func DeleteChatAlert(chatID, alertID int64) (bool, error) {
+	result, err := DB.Exec(`DELETE FROM alerts WHERE chat_id = ? AND id = ?;`, chatID, alertID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete alert %d of chat ID %d: %w", alertID, chatID, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete alert %d of chat ID %d: %w", alertID, chatID, err)
+	}
+	return affected > 0, nil
+}
+
+// DeleteChatAlerts removes every alert of a chat and returns how many there were
+func DeleteChatAlerts(chatID int64) (int64, error) {
+	result, err := DB.Exec(`DELETE FROM alerts WHERE chat_id = ?;`, chatID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete alerts of chat ID %d: %w", chatID, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete alerts of chat ID %d: %w", chatID, err)
+	}
+	return affected, nil
+}
+
+// DeleteUserChatAlerts deletes the alerts a user created in a chat and returns how many were deleted
+func DeleteUserChatAlerts(chatID, userID int64) (int64, error) {
+	result, err := DB.Exec(`DELETE FROM alerts WHERE chat_id = ? AND user_id = ?;`, chatID, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete alerts of user %d in chat ID %d: %w", userID, chatID, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete alerts of user %d in chat ID %d: %w", userID, chatID, err)
+	}
+	return affected, nil
+}
+
+// UpdateAlertTarget changes the target of an alert of a chat, the reference price is reset to the current price
+// and a repeating alert is armed again without the cooldown of its last notification
+func UpdateAlertTarget(chatID, alertID int64, value, alertType, currentPrice string) (bool, error) {
+	query := `
+	UPDATE alerts SET value = ?, alert_type = ?, current_price = ?, armed = 1, last_triggered_at = NULL
+	WHERE chat_id = ? AND id = ?;`
+
+	result, err := DB.Exec(query, value, alertType, currentPrice, chatID, alertID)
+	if err != nil {
+		return false, fmt.Errorf("failed to update alert %d of chat ID %d: %w", alertID, chatID, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to update alert %d of chat ID %d: %w", alertID, chatID, err)
+	}
+	return affected > 0, nil
+}
+
+// GetExpiredAlerts fetches the alerts whose expiration is at or before the given time
+func GetExpiredAlerts(now time.Time) ([]types.Alert, error) {
+	query := `SELECT ` + alertColumns + ` FROM alerts WHERE expires_at IS NOT NULL AND expires_at <= ?;`
+
+	rows, err := DB.Query(query, now.UTC().Format(sqliteTimeLayout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query expired alerts: %w", err)
+	}
+	defer rows.Close()
+
+	var alerts []types.Alert
+	for rows.Next() {
+		alert, err := scanAlert(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		alerts = append(alerts, alert)
+	}
+
+	return alerts, nil
+}
+
+// MarkAlertTriggered disarms a repeating alert after a notification until the price moves back across its threshold
+func MarkAlertTriggered(alertID int64, at time.Time) error {
+	_, err := DB.Exec(`UPDATE alerts SET armed = 0, last_triggered_at = ? WHERE id = ?;`,
+		at.UTC().Format(sqliteTimeLayout), alertID)
+	if err != nil {
+		return fmt.Errorf("failed to mark alert %d as triggered: %w", alertID, err)
+	}
+	return nil
+}
+
+// RearmAlert arms a repeating alert again once the price moved back across its threshold by the hysteresis margin
+func RearmAlert(alertID int64) error {
+	_, err := DB.Exec(`UPDATE alerts SET armed = 1 WHERE id = ?;`, alertID)
+	if err != nil {
+		return fmt.Errorf("failed to re-arm alert %d: %w", alertID, err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package database
+
+import (
+	"coinpaprika-telegram-bot/internal/types"
+	"database/sql"
+	"fmt"
+)
+
+// SetAlias pins a ticker alias to a coin ID for a chat, replacing any previous mapping
+func SetAlias(chatID int64, alias, coinID string) error {
+	query := `
+	INSERT OR REPLACE INTO chat_aliases (chat_id, alias, coin_id)
+	VALUES (?, ?, ?);`
+
+	_, err := DB.Exec(query, chatID, alias, coinID)
+	if err != nil {
+		return fmt.Errorf("failed to set alias %s: %w", alias, err)
+	}
+	return nil
+}
+
+// RemoveAlias deletes a chat's ticker alias, reporting whether it existed
+func RemoveAlias(chatID int64, alias string) (bool, error) {
+	query := `DELETE FROM chat_aliases WHERE chat_id = ? AND alias = ?;`
+	result, err := DB.Exec(query, chatID, alias)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove alias %s: %w", alias, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to remove alias %s: %w", alias, err)
+	}
+	return affected > 0, nil
+}
+
+// GetAlias fetches the coin ID a chat pinned to the alias
+func GetAlias(chatID int64, alias string) (string, bool, error) {
+	var coinID string
+	query := `SELECT coin_id FROM chat_aliases WHERE chat_id = ? AND alias = ?;`
+	err := DB.QueryRow(query, chatID, alias).Scan(&coinID)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, fmt.Errorf("failed to get alias %s: %w", alias, err)
+	}
+	return coinID, true, nil
+}
+
+// GetAliasesByChatID fetches all ticker aliases of a chat ordered by alias
+func GetAliasesByChatID(chatID int64) ([]types.Alias, error) {
+	query := `SELECT alias, coin_id FROM chat_aliases WHERE chat_id = ? ORDER BY alias ASC;`
+
+	rows, err := DB.Query(query, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query aliases for chat ID %d: %w", chatID, err)
+	}
+	defer rows.Close()
+
+	var aliases []types.Alias
+	for rows.Next() {
+		alias := types.Alias{ChatID: chatID}
+		if err := rows.Scan(&alias.Alias, &alias.CoinID); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		aliases = append(aliases, alias)
+	}
+
+	return aliases, nil
+}
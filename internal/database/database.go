@@ -23,13 +23,40 @@ func InitDB(dbPath string) error {
 		value TEXT NOT NULL,
 		alert_type TEXT NOT NULL,
 		current_price TEXT NOT NULL,
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		repeat_interval INTEGER NOT NULL DEFAULT 0,
+		hysteresis REAL NOT NULL DEFAULT 0,
+		armed INTEGER NOT NULL DEFAULT 1,
+		last_triggered_at TIMESTAMP DEFAULT NULL,
+		window_seconds INTEGER NOT NULL DEFAULT 0,
+		quote_ticker TEXT NOT NULL DEFAULT '',
+		expires_at TIMESTAMP DEFAULT NULL,
+		notify_expiry INTEGER NOT NULL DEFAULT 1,
+		user_id INTEGER NOT NULL DEFAULT 0
 	);`
 	_, err = DB.Exec(createTableQuery)
 	if err != nil {
 		return fmt.Errorf("failed to create alerts table: %w", err)
 	}
 
+	// Alerts tables created by older versions lack the columns of repeating, move, pair and expiring alerts
+	alertMigrations := []struct{ column, definition string }{
+		{"repeat_interval", "INTEGER NOT NULL DEFAULT 0"},
+		{"hysteresis", "REAL NOT NULL DEFAULT 0"},
+		{"armed", "INTEGER NOT NULL DEFAULT 1"},
+		{"last_triggered_at", "TIMESTAMP DEFAULT NULL"},
+		{"window_seconds", "INTEGER NOT NULL DEFAULT 0"},
+		{"quote_ticker", "TEXT NOT NULL DEFAULT ''"},
+		{"expires_at", "TIMESTAMP DEFAULT NULL"},
+		{"notify_expiry", "INTEGER NOT NULL DEFAULT 1"},
+		{"user_id", "INTEGER NOT NULL DEFAULT 0"},
+	}
+	for _, m := range alertMigrations {
+		if err := addColumnIfMissing("alerts", m.column, m.definition); err != nil {
+			return err
+		}
+	}
+
 	createMetricsTable := `
 		CREATE TABLE IF NOT EXISTS metrics (
 		metric_name TEXT NOT NULL,
@@ -43,10 +70,133 @@ func InitDB(dbPath string) error {
 		return fmt.Errorf("failed to create metrics table: %w", err)
 	}
 
+	createGlobalSnapshotsTable := `
+	CREATE TABLE IF NOT EXISTS global_snapshots (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		market_cap REAL NOT NULL,
+		volume_24h REAL NOT NULL,
+		btc_dominance REAL NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);`
+	_, err = DB.Exec(createGlobalSnapshotsTable)
+	if err != nil {
+		return fmt.Errorf("failed to create global_snapshots table: %w", err)
+	}
+
+	createWatchlistTable := `
+	CREATE TABLE IF NOT EXISTS watchlist (
+		chat_id INTEGER NOT NULL,
+		coin_id TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (chat_id, coin_id)
+	);`
+	_, err = DB.Exec(createWatchlistTable)
+	if err != nil {
+		return fmt.Errorf("failed to create watchlist table: %w", err)
+	}
+
+	createEventNotificationsTable := `
+	CREATE TABLE IF NOT EXISTS event_notifications (
+		chat_id INTEGER PRIMARY KEY,
+		last_notified_date TEXT NOT NULL DEFAULT ''
+	);`
+	_, err = DB.Exec(createEventNotificationsTable)
+	if err != nil {
+		return fmt.Errorf("failed to create event_notifications table: %w", err)
+	}
+
+	createAliasesTable := `
+	CREATE TABLE IF NOT EXISTS chat_aliases (
+		chat_id INTEGER NOT NULL,
+		alias TEXT NOT NULL,
+		coin_id TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (chat_id, alias)
+	);`
+	_, err = DB.Exec(createAliasesTable)
+	if err != nil {
+		return fmt.Errorf("failed to create chat_aliases table: %w", err)
+	}
+
+	createPortfolioTable := `
+	CREATE TABLE IF NOT EXISTS portfolio_positions (
+		user_id INTEGER NOT NULL,
+		coin_id TEXT NOT NULL,
+		amount REAL NOT NULL,
+		cost_basis REAL NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (user_id, coin_id)
+	);`
+	_, err = DB.Exec(createPortfolioTable)
+	if err != nil {
+		return fmt.Errorf("failed to create portfolio_positions table: %w", err)
+	}
+
+	createPortfolioSnapshotsTable := `
+	CREATE TABLE IF NOT EXISTS portfolio_snapshots (
+		user_id INTEGER NOT NULL,
+		snapshot_date TEXT NOT NULL,
+		coin_id TEXT NOT NULL,
+		value REAL NOT NULL,
+		PRIMARY KEY (user_id, snapshot_date, coin_id)
+	);`
+	_, err = DB.Exec(createPortfolioSnapshotsTable)
+	if err != nil {
+		return fmt.Errorf("failed to create portfolio_snapshots table: %w", err)
+	}
+
+	createDigestsTable := `
+	CREATE TABLE IF NOT EXISTS digests (
+		chat_id INTEGER PRIMARY KEY,
+		frequency TEXT NOT NULL,
+		time_of_day TEXT NOT NULL,
+		timezone TEXT NOT NULL,
+		next_run_at TIMESTAMP NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);`
+	_, err = DB.Exec(createDigestsTable)
+	if err != nil {
+		return fmt.Errorf("failed to create digests table: %w", err)
+	}
+
 	log.Println("Database initialized successfully.")
 	return nil
 }
 
+// addColumnIfMissing adds a column to a table created by an older version of the bot
+func addColumnIfMissing(table, column, definition string) error {
+	rows, err := DB.Query(fmt.Sprintf("PRAGMA table_info(%s);", table))
+	if err != nil {
+		return fmt.Errorf("failed to read columns of %s table: %w", table, err)
+	}
+
+	exists := false
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, columnType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &columnType, &notNull, &defaultValue, &pk); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan columns of %s table: %w", table, err)
+		}
+		if name == column {
+			exists = true
+		}
+	}
+	rows.Close()
+	if exists {
+		return nil
+	}
+
+	_, err = DB.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s;", table, column, definition))
+	if err != nil {
+		return fmt.Errorf("failed to add %s column to %s table: %w", column, table, err)
+	}
+	log.Printf("Added column %s to table %s.", column, table)
+	return nil
+}
+
 func CloseDB() error {
 	if DB != nil {
 		return DB.Close()
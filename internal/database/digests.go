@@ -0,0 +1,94 @@
+package database
+
+import (
+	"coinpaprika-telegram-bot/internal/types"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// SetDigest creates or replaces the digest schedule of a chat
+func SetDigest(digest types.Digest) error {
+	query := `
+	INSERT INTO digests (chat_id, frequency, time_of_day, timezone, next_run_at)
+	VALUES (?, ?, ?, ?, ?)
+	ON CONFLICT(chat_id) DO UPDATE SET
+		frequency = excluded.frequency,
+		time_of_day = excluded.time_of_day,
+		timezone = excluded.timezone,
+		next_run_at = excluded.next_run_at;`
+
+	_, err := DB.Exec(query, digest.ChatID, digest.Frequency, digest.TimeOfDay, digest.Timezone,
+		digest.NextRunAt.UTC().Format(sqliteTimeLayout))
+	if err != nil {
+		return fmt.Errorf("failed to set digest for chat ID %d: %w", digest.ChatID, err)
+	}
+	return nil
+}
+
+// DeleteDigest removes the digest schedule of a chat, reporting whether one existed
+func DeleteDigest(chatID int64) (bool, error) {
+	result, err := DB.Exec(`DELETE FROM digests WHERE chat_id = ?;`, chatID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete digest for chat ID %d: %w", chatID, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete digest for chat ID %d: %w", chatID, err)
+	}
+	return affected > 0, nil
+}
+
+// GetDigest fetches the digest schedule of a chat, nil when none is set
+func GetDigest(chatID int64) (*types.Digest, error) {
+	query := `SELECT chat_id, frequency, time_of_day, timezone, next_run_at FROM digests WHERE chat_id = ?;`
+
+	var digest types.Digest
+	err := DB.QueryRow(query, chatID).Scan(
+		&digest.ChatID, &digest.Frequency, &digest.TimeOfDay, &digest.Timezone, &digest.NextRunAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query digest for chat ID %d: %w", chatID, err)
+	}
+
+	return &digest, nil
+}
+
+// GetDueDigests fetches the digests whose next run is at or before the given time
+func GetDueDigests(now time.Time) ([]types.Digest, error) {
+	query := `
+	SELECT chat_id, frequency, time_of_day, timezone, next_run_at
+	FROM digests
+	WHERE next_run_at <= ?
+	ORDER BY next_run_at ASC;`
+
+	rows, err := DB.Query(query, now.UTC().Format(sqliteTimeLayout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query due digests: %w", err)
+	}
+	defer rows.Close()
+
+	var digests []types.Digest
+	for rows.Next() {
+		var digest types.Digest
+		if err := rows.Scan(&digest.ChatID, &digest.Frequency, &digest.TimeOfDay, &digest.Timezone, &digest.NextRunAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		digests = append(digests, digest)
+	}
+
+	return digests, nil
+}
+
+// SetDigestNextRun stores when the digest of a chat is due next
+func SetDigestNextRun(chatID int64, next time.Time) error {
+	query := `UPDATE digests SET next_run_at = ? WHERE chat_id = ?;`
+	_, err := DB.Exec(query, next.UTC().Format(sqliteTimeLayout), chatID)
+	if err != nil {
+		return fmt.Errorf("failed to update next digest run for chat ID %d: %w", chatID, err)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package database
+
+import (
+	"fmt"
+)
+
+// EnableEventNotifications opts a chat in to the daily events notification
+func EnableEventNotifications(chatID int64) error {
+	query := `INSERT OR IGNORE INTO event_notifications (chat_id) VALUES (?);`
+	_, err := DB.Exec(query, chatID)
+	if err != nil {
+		return fmt.Errorf("failed to enable event notifications: %w", err)
+	}
+	return nil
+}
+
+// DisableEventNotifications opts a chat out of the daily events notification
+func DisableEventNotifications(chatID int64) error {
+	query := `DELETE FROM event_notifications WHERE chat_id = ?;`
+	_, err := DB.Exec(query, chatID)
+	if err != nil {
+		return fmt.Errorf("failed to disable event notifications: %w", err)
+	}
+	return nil
+}
+
+// GetEventNotificationChats fetches opted-in chats with the date they were last notified
+func GetEventNotificationChats() (map[int64]string, error) {
+	query := `SELECT chat_id, last_notified_date FROM event_notifications;`
+
+	rows, err := DB.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query event notifications: %w", err)
+	}
+	defer rows.Close()
+
+	chats := make(map[int64]string)
+	for rows.Next() {
+		var chatID int64
+		var lastNotified string
+		if err := rows.Scan(&chatID, &lastNotified); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		chats[chatID] = lastNotified
+	}
+
+	return chats, nil
+}
+
+// SetEventNotificationDate records the date a chat was last notified
+func SetEventNotificationDate(chatID int64, date string) error {
+	query := `UPDATE event_notifications SET last_notified_date = ? WHERE chat_id = ?;`
+	_, err := DB.Exec(query, date, chatID)
+	if err != nil {
+		return fmt.Errorf("failed to update event notification date: %w", err)
+	}
+	return nil
+}
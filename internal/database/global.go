@@ -0,0 +1,59 @@
+package database
+
+import (
+	"coinpaprika-telegram-bot/internal/types"
+	"fmt"
+	"time"
+)
+
+// sqliteTimeLayout matches the format produced by CURRENT_TIMESTAMP
+const sqliteTimeLayout = "2006-01-02 15:04:05"
+
+// InsertGlobalSnapshot stores the current global market figures
+func InsertGlobalSnapshot(marketCap, volume24h, btcDominance float64) error {
+	query := `
+	INSERT INTO global_snapshots (market_cap, volume_24h, btc_dominance)
+	VALUES (?, ?, ?);`
+
+	_, err := DB.Exec(query, marketCap, volume24h, btcDominance)
+	if err != nil {
+		return fmt.Errorf("failed to insert global snapshot: %w", err)
+	}
+	return nil
+}
+
+// GetGlobalSnapshotsSince fetches global market snapshots recorded after the given time, oldest first
+func GetGlobalSnapshotsSince(since time.Time) ([]types.GlobalSnapshot, error) {
+	query := `
+	SELECT market_cap, volume_24h, btc_dominance, created_at
+	FROM global_snapshots
+	WHERE created_at >= ?
+	ORDER BY created_at ASC;`
+
+	rows, err := DB.Query(query, since.UTC().Format(sqliteTimeLayout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query global snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	var snapshots []types.GlobalSnapshot
+	for rows.Next() {
+		var snapshot types.GlobalSnapshot
+		if err := rows.Scan(&snapshot.MarketCap, &snapshot.Volume24h, &snapshot.BTCDominance, &snapshot.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots, nil
+}
+
+// DeleteGlobalSnapshotsBefore removes snapshots older than the given time
+func DeleteGlobalSnapshotsBefore(before time.Time) error {
+	query := `DELETE FROM global_snapshots WHERE created_at < ?;`
+	_, err := DB.Exec(query, before.UTC().Format(sqliteTimeLayout))
+	if err != nil {
+		return fmt.Errorf("failed to delete global snapshots: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package database
+
+import (
+	"coinpaprika-telegram-bot/internal/types"
+	"database/sql"
+	"fmt"
+)
+
+// AddPosition adds coins to a user's position, averaging the cost basis with any existing holding
+func AddPosition(userID int64, coinID string, amount, price float64) error {
+	query := `
+	INSERT INTO portfolio_positions (user_id, coin_id, amount, cost_basis)
+	VALUES (?, ?, ?, ?)
+	ON CONFLICT (user_id, coin_id) DO UPDATE SET
+		cost_basis = (amount * cost_basis + excluded.amount * excluded.cost_basis) / (amount + excluded.amount),
+		amount = amount + excluded.amount,
+		updated_at = CURRENT_TIMESTAMP;`
+
+	_, err := DB.Exec(query, userID, coinID, amount, price)
+	if err != nil {
+		return fmt.Errorf("failed to add %s to portfolio: %w", coinID, err)
+	}
+	return nil
+}
+
+// ReducePosition sells part of a user's position, removing it once nothing is left.
+// It returns the remaining amount and whether the position existed.
+func ReducePosition(userID int64, coinID string, amount float64) (float64, bool, error) {
+	var current float64
+	query := `SELECT amount FROM portfolio_positions WHERE user_id = ? AND coin_id = ?;`
+	err := DB.QueryRow(query, userID, coinID).Scan(&current)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to query position %s: %w", coinID, err)
+	}
+
+	if amount <= 0 || amount >= current {
+		_, err = RemovePosition(userID, coinID)
+		return 0, true, err
+	}
+
+	query = `
+	UPDATE portfolio_positions SET amount = amount - ?, updated_at = CURRENT_TIMESTAMP
+	WHERE user_id = ? AND coin_id = ?;`
+	if _, err := DB.Exec(query, amount, userID, coinID); err != nil {
+		return 0, true, fmt.Errorf("failed to reduce position %s: %w", coinID, err)
+	}
+	return current - amount, true, nil
+}
+
+// RemovePosition deletes a user's position, reporting whether it existed
+func RemovePosition(userID int64, coinID string) (bool, error) {
+	query := `DELETE FROM portfolio_positions WHERE user_id = ? AND coin_id = ?;`
+	result, err := DB.Exec(query, userID, coinID)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove position %s: %w", coinID, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to remove position %s: %w", coinID, err)
+	}
+	return affected > 0, nil
+}
+
+// GetPortfolio fetches all positions of a user in the order they were opened
+func GetPortfolio(userID int64) ([]types.Position, error) {
+	query := `
+	SELECT user_id, coin_id, amount, cost_basis
+	FROM portfolio_positions
+	WHERE user_id = ?
+	ORDER BY created_at ASC, coin_id ASC;`
+
+	rows, err := DB.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query portfolio for user ID %d: %w", userID, err)
+	}
+	defer rows.Close()
+
+	var positions []types.Position
+	for rows.Next() {
+		var position types.Position
+		if err := rows.Scan(&position.UserID, &position.CoinID, &position.Amount, &position.CostBasis); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		positions = append(positions, position)
+	}
+
+	return positions, nil
+}
+
+// GetPortfolioUserIDs fetches the IDs of all users holding at least one position
+func GetPortfolioUserIDs() ([]int64, error) {
+	rows, err := DB.Query(`SELECT DISTINCT user_id FROM portfolio_positions;`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query portfolio users: %w", err)
+	}
+	defer rows.Close()
+
+	var userIDs []int64
+	for rows.Next() {
+		var userID int64
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	return userIDs, nil
+}
+
+// SavePortfolioSnapshot replaces a user's snapshot of the day with the given value per coin
+func SavePortfolioSnapshot(userID int64, date string, values map[string]float64) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin portfolio snapshot: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM portfolio_snapshots WHERE user_id = ? AND snapshot_date = ?;`, userID, date); err != nil {
+		return fmt.Errorf("failed to clear portfolio snapshot: %w", err)
+	}
+
+	query := `INSERT INTO portfolio_snapshots (user_id, snapshot_date, coin_id, value) VALUES (?, ?, ?, ?);`
+	for coinID, value := range values {
+		if _, err := tx.Exec(query, userID, date, coinID, value); err != nil {
+			return fmt.Errorf("failed to insert portfolio snapshot of %s: %w", coinID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit portfolio snapshot: %w", err)
+	}
+	return nil
+}
+
+// GetPortfolioSnapshotsSince fetches a user's snapshots from the given date on, oldest first
+func GetPortfolioSnapshotsSince(userID int64, date string) ([]types.PortfolioSnapshot, error) {
+	query := `
+	SELECT user_id, snapshot_date, coin_id, value
+	FROM portfolio_snapshots
+	WHERE user_id = ? AND snapshot_date >= ?
+	ORDER BY snapshot_date ASC, coin_id ASC;`
+
+	rows, err := DB.Query(query, userID, date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query portfolio snapshots for user ID %d: %w", userID, err)
+	}
+	defer rows.Close()
+
+	var snapshots []types.PortfolioSnapshot
+	for rows.Next() {
+		var snapshot types.PortfolioSnapshot
+		if err := rows.Scan(&snapshot.UserID, &snapshot.Date, &snapshot.CoinID, &snapshot.Value); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots, nil
+}
+
+// DeletePortfolioSnapshotsBefore removes snapshots older than the given date
+func DeletePortfolioSnapshotsBefore(date string) error {
+	_, err := DB.Exec(`DELETE FROM portfolio_snapshots WHERE snapshot_date < ?;`, date)
+	if err != nil {
+		return fmt.Errorf("failed to delete old portfolio snapshots: %w", err)
+	}
+	return nil
+}
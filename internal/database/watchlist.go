@@ -0,0 +1,47 @@
+package database
+
+import (
+	"fmt"
+)
+
+// AddToWatchlist adds a coin to the chat's watchlist, ignoring duplicates
+func AddToWatchlist(chatID int64, coinID string) error {
+	query := `INSERT OR IGNORE INTO watchlist (chat_id, coin_id) VALUES (?, ?);`
+	_, err := DB.Exec(query, chatID, coinID)
+	if err != nil {
+		return fmt.Errorf("failed to add %s to watchlist: %w", coinID, err)
+	}
+	return nil
+}
+
+// RemoveFromWatchlist removes a coin from the chat's watchlist
+func RemoveFromWatchlist(chatID int64, coinID string) error {
+	query := `DELETE FROM watchlist WHERE chat_id = ? AND coin_id = ?;`
+	_, err := DB.Exec(query, chatID, coinID)
+	if err != nil {
+		return fmt.Errorf("failed to remove %s from watchlist: %w", coinID, err)
+	}
+	return nil
+}
+
+// GetWatchlist fetches the coin IDs watched by a chat in the order they were added
+func GetWatchlist(chatID int64) ([]string, error) {
+	query := `SELECT coin_id FROM watchlist WHERE chat_id = ? ORDER BY created_at ASC, coin_id ASC;`
+
+	rows, err := DB.Query(query, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query watchlist for chat ID %d: %w", chatID, err)
+	}
+	defer rows.Close()
+
+	var coinIDs []string
+	for rows.Next() {
+		var coinID string
+		if err := rows.Scan(&coinID); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		coinIDs = append(coinIDs, coinID)
+	}
+
+	return coinIDs, nil
+}
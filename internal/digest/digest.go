@@ -0,0 +1,67 @@
+package digest
+
+import (
+	"coinpaprika-telegram-bot/internal/database"
+	"coinpaprika-telegram-bot/internal/scheduler"
+	"coinpaprika-telegram-bot/internal/telegram"
+	"log"
+	"time"
+)
+
+const (
+	digestCheckInterval = 1 * time.Minute
+	// digestGracePeriod is how late a digest is still posted, e.g. after a restart, older runs are skipped
+	digestGracePeriod = 1 * time.Hour
+)
+
+// SendDueDigests posts the digests whose scheduled time has come and schedules their next run
+func SendDueDigests(bot *telegram.Bot) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("🔥 Panic recovered in digest scheduler: %v\n", r)
+		}
+	}()
+
+	now := time.Now()
+	digests, err := database.GetDueDigests(now)
+	if err != nil {
+		log.Printf("❌ Failed to fetch due digests: %v\n", err)
+		return
+	}
+
+	for _, digest := range digests {
+		schedule, err := scheduler.New(digest.Frequency, digest.TimeOfDay, digest.Timezone)
+		if err != nil {
+			log.Printf("❌ Invalid digest schedule for Chat ID: %d | Error: %v\n", digest.ChatID, err)
+			continue
+		}
+
+		// The next run is stored before sending, so a crash while sending does not repeat the digest after a restart
+		if err := database.SetDigestNextRun(digest.ChatID, schedule.Next(now)); err != nil {
+			log.Printf("❌ Failed to schedule the next digest for Chat ID: %d | Error: %v\n", digest.ChatID, err)
+			continue
+		}
+
+		if now.Sub(digest.NextRunAt) > digestGracePeriod {
+			log.Printf("⚠️ Skipping digest for Chat ID: %d missed at %s\n", digest.ChatID, digest.NextRunAt.Format(time.RFC3339))
+			continue
+		}
+
+		if err := bot.SendDigest(digest.ChatID); err != nil {
+			log.Printf("❌ Failed to send digest: %v\n", err)
+			continue
+		}
+		log.Printf("✅ Digest sent to Chat ID: %d\n", digest.ChatID)
+	}
+}
+
+// StartDigestService starts a background service posting the scheduled market digests
+func StartDigestService(bot *telegram.Bot) {
+	go func() {
+		for {
+			SendDueDigests(bot)
+			time.Sleep(digestCheckInterval)
+		}
+	}()
+	log.Println("🚀 Digest service started.")
+}
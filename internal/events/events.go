@@ -0,0 +1,105 @@
+package events
+
+import (
+	"coinpaprika-telegram-bot/config"
+	"coinpaprika-telegram-bot/internal/commands"
+	"coinpaprika-telegram-bot/internal/database"
+	"coinpaprika-telegram-bot/internal/telegram"
+	"coinpaprika-telegram-bot/lib/helpers"
+	"coinpaprika-telegram-bot/lib/translation"
+	"github.com/coinpaprika/coinpaprika-api-go-client/v2/coinpaprika"
+	"log"
+	"strings"
+	"time"
+)
+
+// NotifyEvents sends today's events for the watched coins of every opted-in chat, once per day
+func NotifyEvents(bot *telegram.Bot) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("🔥 Panic recovered in events notifier: %v\n", r)
+		}
+	}()
+
+	now := time.Now().UTC()
+	if now.Hour() < config.GetInt("events_notify_hour") {
+		return
+	}
+	today := now.Format("2006-01-02")
+
+	chats, err := database.GetEventNotificationChats()
+	if err != nil {
+		log.Printf("❌ Failed to fetch event notification chats: %v\n", err)
+		return
+	}
+
+	// Events are fetched once per coin even when several chats watch it
+	eventsByCoin := make(map[string][]*coinpaprika.Event)
+
+	for chatID, lastNotified := range chats {
+		if lastNotified == today {
+			continue
+		}
+
+		coinIDs, err := database.GetWatchlist(chatID)
+		if err != nil {
+			log.Printf("❌ Failed to fetch watchlist for Chat ID: %d | Error: %v\n", chatID, err)
+			continue
+		}
+
+		var text strings.Builder
+		for _, coinID := range coinIDs {
+			events, fetched := eventsByCoin[coinID]
+			if !fetched {
+				events, err = commands.GetEventsByCoinID(coinID)
+				if err != nil {
+					log.Printf("⚠️ Failed to fetch events for %s: %v\n", coinID, err)
+				}
+				eventsByCoin[coinID] = events
+			}
+
+			todays := commands.EventsOn(events, now)
+			if len(todays) == 0 {
+				continue
+			}
+
+			c, err := commands.GetCoinByID(coinID)
+			if err != nil {
+				continue
+			}
+			text.WriteString(translation.Translate(
+				"events_today_coin_header",
+				helpers.EscapeMarkdownV2(*c.Name), *c.ID, helpers.EscapeMarkdownV2(*c.Symbol)))
+			for _, event := range todays {
+				text.WriteString(commands.FormatEvent(event))
+			}
+		}
+
+		if text.Len() > 0 {
+			err = bot.SendMessage(telegram.Message{
+				ChatID: int(chatID),
+				Text:   translation.Translate("events_today_header") + text.String(),
+			})
+			if err != nil {
+				log.Printf("❌ Failed to send events notification: %v\n", err)
+				continue
+			}
+			log.Printf("✅ Events notification sent to Chat ID: %d\n", chatID)
+		}
+
+		if err := database.SetEventNotificationDate(chatID, today); err != nil {
+			log.Printf("❌ Failed to store events notification date: %v\n", err)
+		}
+	}
+}
+
+// StartEventService starts a background service posting daily event notifications
+func StartEventService(bot *telegram.Bot) {
+	go func() {
+		for {
+			NotifyEvents(bot)
+			time.Sleep(15 * time.Minute)
+		}
+	}()
+	log.Println("🚀 Events notification service started.")
+}
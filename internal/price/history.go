@@ -0,0 +1,102 @@
+package price
+
+import (
+	"sync"
+	"time"
+)
+
+// MaxHistoryWindow is the longest trailing window kept in the price history
+const MaxHistoryWindow = 24 * time.Hour
+
+// historyCapacity holds MaxHistoryWindow of prices at the 30 second update interval
+const historyCapacity = int(MaxHistoryWindow / (30 * time.Second))
+
+// PricePoint is a price of a coin at the time of an update
+type PricePoint struct {
+	Time     time.Time
+	PriceUSD float64
+}
+
+// priceRing is a fixed size ring buffer of the most recent prices of a coin
+type priceRing struct {
+	points []PricePoint
+	start  int
+	size   int
+}
+
+func newPriceRing(capacity int) *priceRing {
+	return &priceRing{points: make([]PricePoint, capacity)}
+}
+
+// push appends a point, overwriting the oldest one once the buffer is full
+func (r *priceRing) push(point PricePoint) {
+	end := (r.start + r.size) % len(r.points)
+	r.points[end] = point
+	if r.size < len(r.points) {
+		r.size++
+	} else {
+		r.start = (r.start + 1) % len(r.points)
+	}
+}
+
+// since returns the points at or after t, oldest first
+func (r *priceRing) since(t time.Time) []PricePoint {
+	var result []PricePoint
+	for i := 0; i < r.size; i++ {
+		point := r.points[(r.start+i)%len(r.points)]
+		if !point.Time.Before(t) {
+			result = append(result, point)
+		}
+	}
+	return result
+}
+
+// Only the coins watched by windowed alerts keep a history, every coin would take hundreds of megabytes
+var (
+	histories    = make(map[string]*priceRing)
+	historyMutex = sync.RWMutex{}
+)
+
+// TrackHistory sets the coins whose prices are kept in the history.
+// Histories of coins which are still tracked are kept, the others are dropped.
+func TrackHistory(tickerIDs []string) {
+	historyMutex.Lock()
+	defer historyMutex.Unlock()
+
+	tracked := make(map[string]*priceRing, len(tickerIDs))
+	for _, id := range tickerIDs {
+		if ring, exists := histories[id]; exists {
+			tracked[id] = ring
+		} else {
+			tracked[id] = newPriceRing(historyCapacity)
+		}
+	}
+	histories = tracked
+}
+
+// GetPriceHistory returns the prices of a tracked coin within the trailing window, oldest first
+func GetPriceHistory(tickerID string, window time.Duration) ([]PricePoint, bool) {
+	historyMutex.RLock()
+	defer historyMutex.RUnlock()
+
+	ring, exists := histories[tickerID]
+	if !exists {
+		return nil, false
+	}
+	return ring.since(time.Now().Add(-window)), true
+}
+
+// recordHistory appends the latest prices of the tracked coins to their history
+func recordHistory(at time.Time) {
+	historyMutex.Lock()
+	defer historyMutex.Unlock()
+
+	cryptoPricesMutex.RLock()
+	defer cryptoPricesMutex.RUnlock()
+
+	for id, ring := range histories {
+		if p, exists := cryptoPrices[id]; exists && p.PriceUSD > 0 {
+			ring.push(PricePoint{Time: at, PriceUSD: p.PriceUSD})
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package price
+
+import (
+	"coinpaprika-telegram-bot/lib/helpers"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// CoinEntry is a single coin of the in-memory search index
+type CoinEntry struct {
+	ID     string
+	Name   string
+	Symbol string
+	Rank   int64
+}
+
+// coinIndex holds the coins of the last ticker snapshot keyed by lowercased ID, symbol and name
+type coinIndex struct {
+	entries  []CoinEntry
+	byID     map[string]int
+	bySymbol map[string][]int
+	byName   map[string][]int
+}
+
+var (
+	index      = &coinIndex{}
+	indexMutex = sync.RWMutex{}
+)
+
+// buildIndex replaces the search index with the coins of the given snapshot
+func buildIndex(entries []CoinEntry) {
+	idx := &coinIndex{
+		entries:  entries,
+		byID:     make(map[string]int, len(entries)),
+		bySymbol: make(map[string][]int, len(entries)),
+		byName:   make(map[string][]int, len(entries)),
+	}
+
+	// Entries are rank ordered so the first coin of every symbol is the most relevant one
+	sort.SliceStable(idx.entries, func(i, j int) bool {
+		return rankOrder(idx.entries[i].Rank) < rankOrder(idx.entries[j].Rank)
+	})
+
+	for i, entry := range idx.entries {
+		idx.byID[strings.ToLower(entry.ID)] = i
+		symbol := strings.ToLower(entry.Symbol)
+		idx.bySymbol[symbol] = append(idx.bySymbol[symbol], i)
+		name := strings.ToLower(entry.Name)
+		idx.byName[name] = append(idx.byName[name], i)
+	}
+
+	indexMutex.Lock()
+	index = idx
+	indexMutex.Unlock()
+}
+
+// SearchIndex looks the query up in the in-memory index built from the ticker snapshot.
+// Only exact ID, symbol and name matches are returned, ordered by rank.
+func SearchIndex(query string, limit int) []CoinEntry {
+	idx, query := searchableIndex(query, limit)
+	if idx == nil {
+		return nil
+	}
+
+	matches := newIndexMatches(idx, limit)
+	if pos, found := idx.byID[query]; found {
+		matches.add(pos)
+	}
+	matches.add(idx.bySymbol[query]...)
+	matches.add(idx.byName[query]...)
+	return matches.result
+}
+
+// SuggestIndex returns coins whose name starts with the query, or typo-tolerant matches of it.
+// These are guesses, they are offered to the user to choose from and never resolved silently.
+func SuggestIndex(query string, limit int) []CoinEntry {
+	idx, query := searchableIndex(query, limit)
+	if idx == nil {
+		return nil
+	}
+
+	matches := newIndexMatches(idx, limit)
+	for i, entry := range idx.entries {
+		if strings.HasPrefix(strings.ToLower(entry.Name), query) {
+			matches.add(i)
+		}
+	}
+	if len(matches.result) > 0 {
+		return matches.result
+	}
+
+	matches.add(idx.fuzzyMatches(query)...)
+	return matches.result
+}
+
+// searchableIndex returns the current index and the normalized query, or nil when there is nothing to search
+func searchableIndex(query string, limit int) (*coinIndex, string) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" || limit <= 0 {
+		return nil, query
+	}
+
+	indexMutex.RLock()
+	idx := index
+	indexMutex.RUnlock()
+
+	if len(idx.entries) == 0 {
+		return nil, query
+	}
+	return idx, query
+}
+
+// indexMatches collects distinct index entries up to a limit
+type indexMatches struct {
+	idx    *coinIndex
+	limit  int
+	seen   map[int]bool
+	result []CoinEntry
+}
+
+func newIndexMatches(idx *coinIndex, limit int) *indexMatches {
+	return &indexMatches{idx: idx, limit: limit, seen: make(map[int]bool)}
+}
+
+func (m *indexMatches) add(positions ...int) {
+	for _, pos := range positions {
+		if len(m.result) >= m.limit || m.seen[pos] {
+			continue
+		}
+		m.seen[pos] = true
+		m.result = append(m.result, m.idx.entries[pos])
+	}
+}
+
+// fuzzyMatches returns the positions of coins whose symbol or name is within a small edit distance of the query
+func (idx *coinIndex) fuzzyMatches(query string) []int {
+	// Short queries tolerate a single typo, anything shorter than 3 characters is too ambiguous
+	maxDistance := 1
+	if len(query) < 3 {
+		return nil
+	} else if len(query) > 5 {
+		maxDistance = 2
+	}
+
+	type match struct {
+		pos      int
+		distance int
+	}
+
+	var matches []match
+	for i, entry := range idx.entries {
+		distance := helpers.Levenshtein(query, strings.ToLower(entry.Symbol))
+		if d := helpers.Levenshtein(query, strings.ToLower(entry.Name)); d < distance {
+			distance = d
+		}
+		if distance <= maxDistance {
+			matches = append(matches, match{pos: i, distance: distance})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].distance < matches[j].distance })
+
+	positions := make([]int, len(matches))
+	for i, m := range matches {
+		positions[i] = m.pos
+	}
+	return positions
+}
+
+// rankOrder sorts unranked coins (rank 0) after all ranked ones
+func rankOrder(rank int64) int64 {
+	if rank <= 0 {
+		return 1 << 62
+	}
+	return rank
+}
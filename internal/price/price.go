@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
+	"sort"
 	"strconv"
 	"sync"
 	"time"
@@ -14,9 +15,13 @@ type PriceInfo struct {
 	ID             int     `json:"id"`
 	Name           string  `json:"name"`
 	Symbol         string  `json:"symbol"`
+	Rank           int64   `json:"rank"`
 	PriceUSD       float64 `json:"price_usd"`
 	MarketCap      float64 `json:"market_cap"`
+	Volume24h      float64 `json:"volume_24h"`
+	PriceChange1h  float64 `json:"percent_change_1h"`
 	PriceChange24h float64 `json:"percent_change_24h"`
+	PriceChange7d  float64 `json:"percent_change_7d"`
 	LastUpdated    string  `json:"last_updated"`
 }
 
@@ -54,12 +59,16 @@ func fetchCryptoPrices() {
 			ID          string `json:"id"`
 			Name        string `json:"name"`
 			Symbol      string `json:"symbol"`
+			Rank        int64  `json:"rank"`
 			LastUpdated string `json:"last_updated"`
 			Quotes      struct {
 				USD struct {
 					Price          float64 `json:"price"`
 					MarketCap      float64 `json:"market_cap"`
+					Volume24h      float64 `json:"volume_24h"`
+					PriceChange1h  float64 `json:"percent_change_1h"`
 					PriceChange24h float64 `json:"percent_change_24h"`
+					PriceChange7d  float64 `json:"percent_change_7d"`
 				} `json:"USD"`
 			} `json:"quotes"`
 		}
@@ -71,22 +80,36 @@ func fetchCryptoPrices() {
 			continue
 		}
 
+		entries := make([]CoinEntry, 0, len(tickers))
 		cryptoPricesMutex.Lock()
 		for i, ticker := range tickers {
 			cryptoPrices[ticker.ID] = PriceInfo{
 				ID:             i + 1,
 				Name:           ticker.Name,
 				Symbol:         ticker.Symbol,
+				Rank:           ticker.Rank,
 				PriceUSD:       ticker.Quotes.USD.Price,
 				MarketCap:      ticker.Quotes.USD.MarketCap,
+				Volume24h:      ticker.Quotes.USD.Volume24h,
+				PriceChange1h:  ticker.Quotes.USD.PriceChange1h,
 				PriceChange24h: ticker.Quotes.USD.PriceChange24h,
+				PriceChange7d:  ticker.Quotes.USD.PriceChange7d,
 				LastUpdated:    ticker.LastUpdated,
 			}
 
 			idMapping[strconv.Itoa(i+1)] = ticker.ID
+			entries = append(entries, CoinEntry{
+				ID:     ticker.ID,
+				Name:   ticker.Name,
+				Symbol: ticker.Symbol,
+				Rank:   ticker.Rank,
+			})
 		}
 		cryptoPricesMutex.Unlock()
 
+		buildIndex(entries)
+		recordHistory(time.Now())
+
 		log.Println("✅ Cryptocurrency prices updated successfully.")
 
 		time.Sleep(30 * time.Second)
@@ -108,6 +131,19 @@ func GetPrice(tickerID string) (PriceInfo, bool) {
 	return price, exists
 }
 
+// GetRatio returns the ratio of the USD prices of two coins, e.g. the price of ETH in BTC
+func GetRatio(baseID, quoteID string) (float64, bool) {
+	cryptoPricesMutex.RLock()
+	defer cryptoPricesMutex.RUnlock()
+
+	base, baseExists := cryptoPrices[baseID]
+	quote, quoteExists := cryptoPrices[quoteID]
+	if !baseExists || !quoteExists || quote.PriceUSD <= 0 {
+		return 0, false
+	}
+	return base.PriceUSD / quote.PriceUSD, true
+}
+
 func GetTickerByID(ID string) (string, bool) {
 	idMutex.RLock()
 	defer idMutex.RUnlock()
@@ -128,3 +164,27 @@ func GetAllPrices() map[string]PriceInfo {
 	}
 	return copy
 }
+
+// GetTopMovers returns the biggest 24h gainers and losers among the coins ranked within the top rank positions
+func GetTopMovers(rank, limit int) (gainers, losers []PriceInfo) {
+	cryptoPricesMutex.RLock()
+	var candidates []PriceInfo
+	for _, p := range cryptoPrices {
+		if p.Rank > 0 && p.Rank <= int64(rank) && p.PriceUSD > 0 {
+			candidates = append(candidates, p)
+		}
+	}
+	cryptoPricesMutex.RUnlock()
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].PriceChange24h > candidates[j].PriceChange24h
+	})
+
+	for i := 0; i < len(candidates) && i < limit && candidates[i].PriceChange24h > 0; i++ {
+		gainers = append(gainers, candidates[i])
+	}
+	for i := len(candidates) - 1; i >= 0 && len(losers) < limit && candidates[i].PriceChange24h < 0; i-- {
+		losers = append(losers, candidates[i])
+	}
+	return gainers, losers
+}
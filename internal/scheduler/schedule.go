@@ -0,0 +1,137 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	Daily  = "daily"
+	Hourly = "hourly"
+)
+
+// ErrInvalidSchedule is returned when a schedule cannot be parsed
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+// Schedule is a recurring wall-clock time in a timezone, either every day at Hour:Minute or every hour at Minute
+type Schedule struct {
+	Frequency string
+	Hour      int
+	Minute    int
+	Location  *time.Location
+}
+
+// Parse parses "daily 09:00 [timezone]" or "hourly [:30] [timezone]", using defaultLocation when no timezone is given
+func Parse(args []string, defaultLocation *time.Location) (Schedule, error) {
+	if len(args) == 0 {
+		return Schedule{}, ErrInvalidSchedule
+	}
+
+	s := Schedule{Frequency: strings.ToLower(args[0]), Location: defaultLocation}
+	args = args[1:]
+
+	switch s.Frequency {
+	case Daily:
+		if len(args) == 0 {
+			return Schedule{}, ErrInvalidSchedule
+		}
+		hour, minute, err := parseClock(args[0])
+		if err != nil {
+			return Schedule{}, err
+		}
+		s.Hour, s.Minute = hour, minute
+		args = args[1:]
+	case Hourly:
+		if len(args) > 0 {
+			if minute, err := parseMinute(args[0]); err == nil {
+				s.Minute = minute
+				args = args[1:]
+			}
+		}
+	default:
+		return Schedule{}, ErrInvalidSchedule
+	}
+
+	if len(args) > 1 {
+		return Schedule{}, ErrInvalidSchedule
+	}
+	if len(args) == 1 {
+		location, err := time.LoadLocation(args[0])
+		if err != nil {
+			return Schedule{}, fmt.Errorf("%w: unknown timezone %s", ErrInvalidSchedule, args[0])
+		}
+		s.Location = location
+	}
+
+	return s, nil
+}
+
+// New rebuilds a stored schedule, the clock is formatted as by Clock
+func New(frequency, clock, timezone string) (Schedule, error) {
+	hour, minute, err := parseClock(clock)
+	if err != nil {
+		return Schedule{}, err
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return Schedule{}, fmt.Errorf("%w: unknown timezone %s", ErrInvalidSchedule, timezone)
+	}
+	if frequency != Daily && frequency != Hourly {
+		return Schedule{}, ErrInvalidSchedule
+	}
+
+	return Schedule{Frequency: frequency, Hour: hour, Minute: minute, Location: location}, nil
+}
+
+// Next returns the first run of the schedule strictly after the given time
+func (s Schedule) Next(after time.Time) time.Time {
+	local := after.In(s.Location)
+
+	if s.Frequency == Hourly {
+		next := time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), s.Minute, 0, 0, s.Location)
+		if !next.After(after) {
+			next = next.Add(time.Hour)
+		}
+		return next
+	}
+
+	// Days are advanced on the calendar so runs stay at the same wall-clock time across DST changes
+	next := time.Date(local.Year(), local.Month(), local.Day(), s.Hour, s.Minute, 0, 0, s.Location)
+	for !next.After(after) {
+		local = local.AddDate(0, 0, 1)
+		next = time.Date(local.Year(), local.Month(), local.Day(), s.Hour, s.Minute, 0, 0, s.Location)
+	}
+	return next
+}
+
+// Clock returns the time of day as HH:MM, for hourly schedules only the minute is meaningful
+func (s Schedule) Clock() string {
+	return fmt.Sprintf("%02d:%02d", s.Hour, s.Minute)
+}
+
+func parseClock(text string) (int, int, error) {
+	parts := strings.Split(text, ":")
+	if len(parts) != 2 {
+		return 0, 0, ErrInvalidSchedule
+	}
+	hour, err := strconv.Atoi(parts[0])
+	if err != nil || hour < 0 || hour > 23 {
+		return 0, 0, ErrInvalidSchedule
+	}
+	minute, err := parseMinute(parts[1])
+	if err != nil {
+		return 0, 0, err
+	}
+	return hour, minute, nil
+}
+
+func parseMinute(text string) (int, error) {
+	minute, err := strconv.Atoi(strings.TrimPrefix(text, ":"))
+	if err != nil || minute < 0 || minute > 59 {
+		return 0, ErrInvalidSchedule
+	}
+	return minute, nil
+}
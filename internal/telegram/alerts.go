@@ -0,0 +1,801 @@
+package telegram
+
+import (
+	"coinpaprika-telegram-bot/internal/commands"
+	"coinpaprika-telegram-bot/internal/database"
+	"coinpaprika-telegram-bot/internal/price"
+	"coinpaprika-telegram-bot/internal/types"
+	"coinpaprika-telegram-bot/lib/helpers"
+	"coinpaprika-telegram-bot/lib/translation"
+	"fmt"
+	"github.com/coinpaprika/coinpaprika-api-go-client/v2/coinpaprika"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultAlertHysteresis = 1.0
+	maxAlertHysteresis     = 50.0
+	minAlertRepeat         = time.Minute
+	maxAlertRepeat         = 30 * 24 * time.Hour
+	minAlertWindow         = 5 * time.Minute
+	maxAlertRank           = 1000
+	maxAlertExpiry         = 365 * 24 * time.Hour
+	averageVolumeDays      = 7
+)
+
+// errInvalidAlertTarget is returned when an alert target is neither a price nor a percentage
+var errInvalidAlertTarget = errors.New("invalid alert target")
+
+// errInvalidAlertWindow is returned when the trailing window of a move alert cannot be parsed
+var errInvalidAlertWindow = errors.New("invalid alert window")
+
+// errInvalidAlertExpiry is returned when the expiration of an alert cannot be parsed
+var errInvalidAlertExpiry = errors.New("invalid alert expiry")
+
+// errInvalidAlertRepeat is returned when the repeat options of an alert cannot be parsed
+var errInvalidAlertRepeat = errors.New("invalid alert repeat options")
+
+// alertMetricKeywords maps the keywords of volume, market cap and rank alerts to their metric
+var alertMetricKeywords = map[string]string{
+	"vol":       "volume",
+	"volume":    "volume",
+	"mcap":      "mcap",
+	"cap":       "mcap",
+	"marketcap": "mcap",
+	"rank":      "rank",
+}
+
+// alertTypeMetrics maps the alert types of volume, market cap and rank alerts to their metric
+var alertTypeMetrics = map[string]string{
+	"volume":         "volume",
+	"volume_percent": "volume",
+	"mcap":           "mcap",
+	"rank":           "rank",
+}
+
+var compactMultipliers = map[byte]float64{'k': 1e3, 'm': 1e6, 'b': 1e9, 't': 1e12}
+
+// alertOptionKeywords start the options following the target and the move window of an alert
+var alertOptionKeywords = map[string]bool{"repeat": true, "for": true, "until": true, "silent": true}
+
+// repeatIntervalRegex matches the intervals of repeating and move alerts
+var repeatIntervalRegex = regexp.MustCompile(`^(\d+)([mhd])$`)
+
+// repeatIntervalUnits maps the units of an interval such as "30m", "4h" or "1d"
+var repeatIntervalUnits = map[string]time.Duration{"m": time.Minute, "h": time.Hour, "d": 24 * time.Hour}
+
+// HandleAlertListCommand sends the active alerts of a chat with delete and edit buttons on every alert
+func (b *Bot) HandleAlertListCommand(chatID int64) string {
+	text, keyboard, err := b.alertList(chatID)
+	if err != nil || keyboard == nil {
+		return text
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "MarkdownV2"
+	msg.ReplyMarkup = keyboard
+	if _, err := b.Bot.Send(msg); err != nil {
+		log.Error("error sending alert list:", err)
+		return translation.Translate("fetch_alerts_failed")
+	}
+	return ""
+}
+
+// alertList renders the active alerts of a chat, the keyboard is nil when there are none
+func (b *Bot) alertList(chatID int64) (string, *tgbotapi.InlineKeyboardMarkup, error) {
+	alerts, err := database.GetAlertsByChatID(chatID)
+	if err != nil {
+		log.Error(translation.Translate("error_fetching_alerts"), err)
+		return translation.Translate("fetch_alerts_failed"), nil, err
+	}
+
+	if len(alerts) == 0 {
+		return translation.Translate("no_active_alerts"), nil, nil
+	}
+
+	var alertList strings.Builder
+	var rows [][]tgbotapi.InlineKeyboardButton
+	alertList.WriteString(translation.Translate("active_alerts_list_header"))
+	for _, alert := range alerts {
+		c, err := commands.GetCoinByID(alert.Ticker)
+		if err != nil {
+			continue
+		}
+		name, symbol := *c.Name, *c.Symbol
+		if alert.QuoteTicker != "" {
+			q, err := commands.GetCoinByID(alert.QuoteTicker)
+			if err != nil {
+				continue
+			}
+			name, symbol = *c.Name+" / "+*q.Name, *c.Symbol+"/"+*q.Symbol
+		}
+
+		var targetString string
+		if alert.AlertType == "price" && alert.QuoteTicker != "" {
+			targetString = "🎯 " + formatAlertTarget(alert)
+		} else if alert.AlertType == "percent" {
+			targetString = fmt.Sprintf(translation.Translate("alert_target_percent"), helpers.FormatPercentage(alert.Target))
+		} else if alert.AlertType == "price" {
+			targetString = fmt.Sprintf(translation.Translate("alert_target_price"), helpers.FormatPriceUS(alert.Target, true))
+		} else if _, known := alertTypeMetrics[alert.AlertType]; known || alert.AlertType == "window" {
+			targetString = "🎯 " + formatAlertTarget(alert)
+		} else {
+			targetString = fmt.Sprintf(translation.Translate("alert_target_generic"), helpers.FormatPriceUS(alert.Target, true))
+		}
+
+		if alert.RepeatInterval > 0 {
+			targetString += "  " + formatAlertRepeat(alert.RepeatInterval, alert.Hysteresis)
+		}
+		if !alert.ExpiresAt.IsZero() {
+			targetString += "  " + formatAlertExpiry(alert.ExpiresAt)
+		}
+
+		formattedDate := helpers.EscapeMarkdownV2(helpers.FormatDate(alert.CreatedAt))
+
+		alertList.WriteString(fmt.Sprintf(
+			translation.Translate("alert_list_item_format"),
+			helpers.EscapeMarkdownV2(name),
+			helpers.EscapeMarkdownV2(symbol),
+			alert.ID,
+			targetString,
+			formattedDate,
+		))
+
+		label := fmt.Sprintf("#%d %s", alert.ID, symbol)
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🗑 "+label, fmt.Sprintf("alert_delete|%d", alert.ID)),
+			tgbotapi.NewInlineKeyboardButtonData("✏️ "+label, fmt.Sprintf("alert_edit|%d", alert.ID)),
+		))
+	}
+
+	if len(rows) == 0 {
+		return alertList.String(), nil, nil
+	}
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return alertList.String(), &keyboard, nil
+}
+
+// handlePairAlert sets an alert on the ratio of two coins, e.g. /alert eth/btc 0.05.
+// Both coins are resolved to their best match as a pair cannot be picked from the search buttons.
+func (b *Bot) handlePairAlert(chatID, userID int64, base, quote, target string) string {
+	baseCoin, err := commands.ResolveCoin(chatID, base)
+	if err != nil {
+		log.Debugf("unable to resolve %s for a pair alert: %v", base, err)
+		return translation.Translate("alert_pair_not_found", helpers.EscapeMarkdownV2(base))
+	}
+	quoteCoin, err := commands.ResolveCoin(chatID, quote)
+	if err != nil {
+		log.Debugf("unable to resolve %s for a pair alert: %v", quote, err)
+		return translation.Translate("alert_pair_not_found", helpers.EscapeMarkdownV2(quote))
+	}
+	if *baseCoin.ID == *quoteCoin.ID {
+		return translation.Translate("alert_pair_invalid")
+	}
+
+	successMsg, err := b.InsertAlert(chatID, userID, baseCoin, quoteCoin, target)
+	if err != nil {
+		return err.Error()
+	}
+	return successMsg
+}
+
+// handleAlertDelete handles /alert delete <id>
+func (b *Bot) handleAlertDelete(u tgbotapi.Update, args string) string {
+	alertID, ok := parseAlertID(args)
+	if !ok {
+		return translation.Translate("alert_manage_usage")
+	}
+	if !b.mayManageAlert(u.Message, alertID) {
+		return translation.Translate("alert_owner_only")
+	}
+
+	return b.deleteAlert(u.Message.Chat.ID, alertID)
+}
+
+// handleAlertClear handles /alert clear, which deletes every alert of the chat for admins
+// and the alerts the sender set for other members
+func (b *Bot) handleAlertClear(u tgbotapi.Update) string {
+	if !b.isChatAdmin(u.Message) {
+		if u.Message.From == nil {
+			return translation.Translate("alert_owner_only")
+		}
+
+		deleted, err := database.DeleteUserChatAlerts(u.Message.Chat.ID, u.Message.From.ID)
+		if err != nil {
+			log.Error(err)
+			return translation.Translate("alert_update_failed")
+		}
+		if deleted == 0 {
+			return translation.Translate("no_own_alerts")
+		}
+		return translation.Translate("own_alerts_cleared", deleted)
+	}
+
+	deleted, err := database.DeleteChatAlerts(u.Message.Chat.ID)
+	if err != nil {
+		log.Error(err)
+		return translation.Translate("alert_update_failed")
+	}
+	if deleted == 0 {
+		return translation.Translate("no_active_alerts")
+	}
+	return translation.Translate("alerts_cleared", deleted)
+}
+
+// handleAlertEdit handles /alert edit <id> <target>
+func (b *Bot) handleAlertEdit(u tgbotapi.Update, args string) string {
+	fields := strings.Fields(args)
+	if len(fields) != 2 {
+		return translation.Translate("alert_manage_usage")
+	}
+	alertID, ok := parseAlertID(fields[0])
+	if !ok {
+		return translation.Translate("alert_manage_usage")
+	}
+	if !b.mayManageAlert(u.Message, alertID) {
+		return translation.Translate("alert_owner_only")
+	}
+
+	return b.updateAlertTarget(u.Message.Chat.ID, alertID, fields[1])
+}
+
+// editAlertFromReply applies the target replied to an edit prompt, the prompt stays open until a valid target is given
+func (b *Bot) editAlertFromReply(message *tgbotapi.Message, alertID int64, target string) string {
+	if !b.mayManageAlert(message, alertID) {
+		return translation.Translate("alert_owner_only")
+	}
+
+	prompt := chatMessage{ChatID: message.Chat.ID, MessageID: message.ReplyToMessage.MessageID}
+
+	// Only alerts of the replying chat are found, a prompt can never edit the alert of another chat
+	alert, _, reply := b.chatAlertCoin(message.Chat.ID, alertID)
+	if alert == nil {
+		delete(b.alertEditMap, prompt)
+		return reply
+	}
+
+	if _, _, err := parseAlertCondition(alertTypeMetrics[alert.AlertType], target); err != nil {
+		return translation.Translate("alert_edit_invalid_target", helpers.EscapeMarkdownV2(target))
+	}
+
+	delete(b.alertEditMap, prompt)
+	return b.updateAlertTarget(message.Chat.ID, alertID, target)
+}
+
+// mayManageAlert reports whether the sender of a message may delete or edit an alert of the chat.
+// Admins manage every alert, other members the alerts they set.
+func (b *Bot) mayManageAlert(message *tgbotapi.Message, alertID int64) bool {
+	return b.isAlertCreator(message.Chat.ID, message.From, alertID) || b.isChatAdmin(message)
+}
+
+// alertCreatorID returns the member setting an alert with a message, 0 for messages sent on behalf of a chat
+func alertCreatorID(message *tgbotapi.Message) int64 {
+	if message.From == nil || message.SenderChat != nil {
+		return 0
+	}
+	return message.From.ID
+}
+
+// isAlertCreator reports whether a user set an alert of the chat, alerts without a recorded creator have none
+func (b *Bot) isAlertCreator(chatID int64, user *tgbotapi.User, alertID int64) bool {
+	if user == nil {
+		return false
+	}
+
+	alert, err := database.GetChatAlert(chatID, alertID)
+	if err != nil {
+		log.Error(err)
+		return false
+	}
+	return alert != nil && alert.UserID != 0 && alert.UserID == user.ID
+}
+
+// deleteAlert deletes an alert of a chat and returns the reply
+func (b *Bot) deleteAlert(chatID, alertID int64) string {
+	alert, c, reply := b.chatAlertCoin(chatID, alertID)
+	if alert == nil {
+		return reply
+	}
+
+	if _, err := database.DeleteChatAlert(chatID, alertID); err != nil {
+		log.Error(err)
+		return translation.Translate("alert_update_failed")
+	}
+	return translation.Translate("alert_deleted", alertID, helpers.EscapeMarkdownV2(*c.Name), helpers.EscapeMarkdownV2(*c.Symbol))
+}
+
+// updateAlertTarget changes the target of an alert of a chat and returns the reply.
+// The current value becomes the new reference, so the direction and percent base follow the new target.
+// The metric of volume, market cap and rank alerts is kept, so their new target is given without a keyword.
+func (b *Bot) updateAlertTarget(chatID, alertID int64, target string) string {
+	alert, c, reply := b.chatAlertCoin(chatID, alertID)
+	if alert == nil {
+		return reply
+	}
+
+	alertType, targetValue, err := parseAlertCondition(alertTypeMetrics[alert.AlertType], target)
+	if err != nil {
+		return translation.Translate("alert_edit_invalid_target", helpers.EscapeMarkdownV2(target))
+	}
+	// A move alert keeps its window, so its new target must be a percentage again
+	if alert.AlertType == "window" {
+		if alertType != "percent" {
+			return translation.Translate("alert_edit_window_target", helpers.EscapeMarkdownV2(target))
+		}
+		alertType = "window"
+	}
+
+	cp, exists := price.GetPrice(alert.Ticker)
+	if !exists {
+		return translation.Translate("current_price_not_found")
+	}
+
+	reference, err := alertReference(alertType, c, cp)
+	if err != nil {
+		log.Error(err)
+		return translation.Translate("alert_reference_unavailable")
+	}
+	if alert.QuoteTicker != "" {
+		ratio, found := price.GetRatio(alert.Ticker, alert.QuoteTicker)
+		if !found {
+			return translation.Translate("current_price_not_found")
+		}
+		reference = ratio
+	}
+
+	updated, err := database.UpdateAlertTarget(chatID, alertID,
+		strconv.FormatFloat(targetValue, 'f', -1, 64), alertType, strconv.FormatFloat(reference, 'f', -1, 64))
+	if err != nil {
+		log.Error(err)
+		return translation.Translate("alert_update_failed")
+	}
+	if !updated {
+		return translation.Translate("alert_not_found", alertID)
+	}
+
+	return translation.Translate(
+		"alert_updated",
+		alertID,
+		helpers.EscapeMarkdownV2(*c.Name),
+		helpers.EscapeMarkdownV2(*c.Symbol),
+		formatAlertTarget(types.Alert{
+			AlertType:    alertType,
+			Target:       targetValue,
+			CurrentPrice: reference,
+			Window:       alert.Window,
+			QuoteTicker:  alert.QuoteTicker,
+		}),
+	)
+}
+
+// chatAlertCoin looks up an alert of a chat and its coin, returning the reply to send when either is missing
+func (b *Bot) chatAlertCoin(chatID, alertID int64) (*types.Alert, *coinpaprika.Coin, string) {
+	alert, err := database.GetChatAlert(chatID, alertID)
+	if err != nil {
+		log.Error(err)
+		return nil, nil, translation.Translate("alert_update_failed")
+	}
+	if alert == nil {
+		return nil, nil, translation.Translate("alert_not_found", alertID)
+	}
+
+	c, err := commands.GetCoinByID(alert.Ticker)
+	if err != nil {
+		log.Error(err)
+		return nil, nil, translation.Translate("coin_search_failed")
+	}
+	return alert, c, ""
+}
+
+// handleAlertDeleteCallback deletes an alert from its button and refreshes the alert list in place
+func (b *Bot) handleAlertDeleteCallback(callbackQuery *tgbotapi.CallbackQuery) {
+	chatID := callbackQuery.Message.Chat.ID
+	alertID, ok := parseAlertCallback(callbackQuery.Data)
+	if !ok {
+		b.Bot.Send(tgbotapi.NewCallback(callbackQuery.ID, translation.Translate("Invalid alert data.")))
+		return
+	}
+	if !b.isAlertCreator(chatID, callbackQuery.From, alertID) && !b.isChatMemberAdmin(callbackQuery.Message.Chat, callbackQuery.From) {
+		b.Bot.Send(tgbotapi.NewCallback(callbackQuery.ID, translation.Translate("admin_only_toast")))
+		return
+	}
+
+	deleted, err := database.DeleteChatAlert(chatID, alertID)
+	if err != nil {
+		log.Error(err)
+		b.Bot.Send(tgbotapi.NewCallback(callbackQuery.ID, translation.Translate("alert_update_failed_toast")))
+		return
+	}
+	if deleted {
+		b.Bot.Send(tgbotapi.NewCallback(callbackQuery.ID, translation.Translate("alert_deleted_toast", alertID)))
+	} else {
+		b.Bot.Send(tgbotapi.NewCallback(callbackQuery.ID, translation.Translate("alert_not_found_toast")))
+	}
+
+	text, keyboard, err := b.alertList(chatID)
+	if err != nil {
+		return
+	}
+	edit := tgbotapi.NewEditMessageText(chatID, callbackQuery.Message.MessageID, text)
+	edit.ParseMode = "MarkdownV2"
+	edit.ReplyMarkup = keyboard
+	if _, err := b.Bot.Send(edit); err != nil {
+		log.Error("error refreshing alert list:", err)
+	}
+}
+
+// handleAlertEditCallback asks for the new target of an alert, the answer is handled by HandleReply
+func (b *Bot) handleAlertEditCallback(callbackQuery *tgbotapi.CallbackQuery) {
+	chatID := callbackQuery.Message.Chat.ID
+	alertID, ok := parseAlertCallback(callbackQuery.Data)
+	if !ok {
+		b.Bot.Send(tgbotapi.NewCallback(callbackQuery.ID, translation.Translate("Invalid alert data.")))
+		return
+	}
+	if !b.isAlertCreator(chatID, callbackQuery.From, alertID) && !b.isChatMemberAdmin(callbackQuery.Message.Chat, callbackQuery.From) {
+		b.Bot.Send(tgbotapi.NewCallback(callbackQuery.ID, translation.Translate("admin_only_toast")))
+		return
+	}
+
+	alert, c, _ := b.chatAlertCoin(chatID, alertID)
+	if alert == nil {
+		b.Bot.Send(tgbotapi.NewCallback(callbackQuery.ID, translation.Translate("alert_not_found_toast")))
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, translation.Translate(
+		"alert_edit_prompt", alertID, helpers.EscapeMarkdownV2(*c.Name), helpers.EscapeMarkdownV2(*c.Symbol)))
+	msg.ParseMode = "MarkdownV2"
+	msg.ReplyMarkup = tgbotapi.ForceReply{
+		ForceReply:            true,
+		Selective:             true,
+		InputFieldPlaceholder: translation.Translate("alert_edit_placeholder"),
+	}
+
+	m, err := b.Bot.Send(msg)
+	if err != nil {
+		log.Error("Failed to prompt for alert target: ", err)
+		b.Bot.Send(tgbotapi.NewCallback(callbackQuery.ID, translation.Translate("Failed to prompt for a reply. Please try again.")))
+		return
+	}
+
+	b.Bot.Send(tgbotapi.NewCallback(callbackQuery.ID, ""))
+	b.storePendingEdit(chatMessage{ChatID: chatID, MessageID: m.MessageID}, alertID)
+}
+
+// pendingPromptLifetime is how long coin pickers and edit prompts wait for an answer
+const pendingPromptLifetime = 1 * time.Hour
+
+// storePendingAlert remembers the target of a coin picker, dropping the pickers nobody answered in time
+func (b *Bot) storePendingAlert(key chatMessage, pending pendingAlert) {
+	now := time.Now()
+	for k, p := range b.alertSpecMap {
+		if now.Sub(p.CreatedAt) > pendingPromptLifetime {
+			delete(b.alertSpecMap, k)
+		}
+	}
+	pending.CreatedAt = now
+	b.alertSpecMap[key] = pending
+}
+
+// takePendingAlert returns and forgets the target of a coin picker which has not expired
+func (b *Bot) takePendingAlert(key chatMessage) (pendingAlert, bool) {
+	pending, exists := b.alertSpecMap[key]
+	delete(b.alertSpecMap, key)
+	if !exists || time.Since(pending.CreatedAt) > pendingPromptLifetime {
+		return pendingAlert{}, false
+	}
+	return pending, true
+}
+
+// storePendingEdit remembers the alert of an edit prompt, dropping the prompts nobody answered in time
+func (b *Bot) storePendingEdit(key chatMessage, alertID int64) {
+	now := time.Now()
+	for k, pending := range b.alertEditMap {
+		if now.Sub(pending.CreatedAt) > pendingPromptLifetime {
+			delete(b.alertEditMap, k)
+		}
+	}
+	b.alertEditMap[key] = pendingEdit{AlertID: alertID, CreatedAt: now}
+}
+
+// pendingEditAlert returns the alert of an edit prompt which has not expired, the prompt stays open until it is answered
+func (b *Bot) pendingEditAlert(key chatMessage) (int64, bool) {
+	pending, exists := b.alertEditMap[key]
+	if !exists {
+		return 0, false
+	}
+	if time.Since(pending.CreatedAt) > pendingPromptLifetime {
+		delete(b.alertEditMap, key)
+		return 0, false
+	}
+	return pending.AlertID, true
+}
+
+// alertOptions are the optional settings following the target of an alert: "5% 1h repeat 30m 2% for 7d"
+type alertOptions struct {
+	Window     time.Duration // trailing window of a move alert, 0 compares with the price when the alert was set
+	Repeat     time.Duration // cooldown between the notifications of a repeating alert, 0 fires once
+	Hysteresis float64       // percent the value must move back across the threshold to re-arm a repeating alert
+	ExpiresAt  time.Time     // when the alert is removed, zero keeps it until it fires
+	Silent     bool          // no notice when the alert expires without triggering
+}
+
+// parseAlertSpec splits an alert target into its metric, target and options, explaining invalid options to the user
+func parseAlertSpec(spec string, now time.Time) (string, string, alertOptions, error) {
+	metric, target := splitAlertMetric(spec)
+	target, opts, err := parseAlertOptions(target, now)
+	switch {
+	case err == errInvalidAlertWindow:
+		return "", "", opts, errors.New(translation.Translate("alert_window_invalid"))
+	case err == errInvalidAlertExpiry:
+		return "", "", opts, errors.New(translation.Translate("alert_expiry_invalid"))
+	case err != nil:
+		return "", "", opts, errors.New(translation.Translate("alert_repeat_invalid"))
+	}
+	return metric, target, opts, nil
+}
+
+// parseAlertOptions splits the target of an alert from its options: a move window right after the target, then in
+// any order "repeat <interval> [hysteresis%]", "for <interval>" or "until <YYYY-MM-DD>" and "silent".
+// A repeating alert without a hysteresis re-arms once the value moves back 1% across the threshold.
+func parseAlertOptions(spec string, now time.Time) (string, alertOptions, error) {
+	var opts alertOptions
+	fields := strings.Fields(strings.ToLower(spec))
+	if len(fields) == 0 {
+		return "", opts, errInvalidAlertTarget
+	}
+
+	rest := fields[1:]
+	if len(rest) > 0 && !alertOptionKeywords[rest[0]] {
+		window, ok := parseAlertInterval(rest[0])
+		if !ok || window < minAlertWindow || window > price.MaxHistoryWindow {
+			return "", opts, errInvalidAlertWindow
+		}
+		opts.Window = window
+		rest = rest[1:]
+	}
+
+	for len(rest) > 0 {
+		switch rest[0] {
+		case "repeat":
+			if len(rest) < 2 || opts.Repeat > 0 {
+				return "", opts, errInvalidAlertRepeat
+			}
+			repeat, ok := parseAlertInterval(rest[1])
+			if !ok || repeat < minAlertRepeat || repeat > maxAlertRepeat {
+				return "", opts, errInvalidAlertRepeat
+			}
+			opts.Repeat, opts.Hysteresis = repeat, defaultAlertHysteresis
+			rest = rest[2:]
+
+			if len(rest) > 0 && !alertOptionKeywords[rest[0]] {
+				value, err := strconv.ParseFloat(strings.TrimSuffix(rest[0], "%"), 64)
+				if err != nil || value < 0 || value > maxAlertHysteresis {
+					return "", opts, errInvalidAlertRepeat
+				}
+				opts.Hysteresis = value
+				rest = rest[1:]
+			}
+		case "for", "until":
+			if len(rest) < 2 || !opts.ExpiresAt.IsZero() {
+				return "", opts, errInvalidAlertExpiry
+			}
+			expiresAt, ok := parseAlertExpiry(rest[0], rest[1], now)
+			if !ok {
+				return "", opts, errInvalidAlertExpiry
+			}
+			opts.ExpiresAt = expiresAt
+			rest = rest[2:]
+		case "silent":
+			opts.Silent = true
+			rest = rest[1:]
+		default:
+			return "", opts, errInvalidAlertRepeat
+		}
+	}
+
+	if opts.Silent && opts.ExpiresAt.IsZero() {
+		return "", opts, errInvalidAlertExpiry
+	}
+	return fields[0], opts, nil
+}
+
+// parseAlertExpiry parses "for <interval>" or "until <YYYY-MM-DD>", an alert set until a date lasts through that day
+func parseAlertExpiry(keyword, value string, now time.Time) (time.Time, bool) {
+	var expiresAt time.Time
+	if keyword == "for" {
+		duration, ok := parseAlertInterval(value)
+		if !ok || duration < minAlertRepeat {
+			return expiresAt, false
+		}
+		expiresAt = now.Add(duration)
+	} else {
+		day, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return expiresAt, false
+		}
+		expiresAt = day.AddDate(0, 0, 1)
+	}
+
+	if !expiresAt.After(now) || expiresAt.Sub(now) > maxAlertExpiry {
+		return expiresAt, false
+	}
+	return expiresAt, true
+}
+
+// formatAlertExpiry describes when an alert expires
+func formatAlertExpiry(expiresAt time.Time) string {
+	return translation.Translate("alert_expiry_label", helpers.EscapeMarkdownV2(expiresAt.UTC().Format("Jan 2, 2006 15:04")))
+}
+
+// parseAlertInterval parses an interval such as "30m", "4h" or "1d"
+func parseAlertInterval(text string) (time.Duration, bool) {
+	matches := repeatIntervalRegex.FindStringSubmatch(text)
+	if matches == nil {
+		return 0, false
+	}
+	n, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return 0, false
+	}
+	return time.Duration(n) * repeatIntervalUnits[matches[2]], true
+}
+
+// formatAlertRepeat describes the cooldown and hysteresis of a repeating alert
+func formatAlertRepeat(repeatInterval int64, hysteresis float64) string {
+	return translation.Translate(
+		"alert_repeat_label",
+		helpers.FormatInterval(time.Duration(repeatInterval)*time.Second),
+		helpers.EscapeMarkdownV2(strconv.FormatFloat(hysteresis, 'f', -1, 64)),
+	)
+}
+
+// parseAlertTarget parses a price (98000) or percent (10% or -5) target into its alert type and value
+func parseAlertTarget(target string) (string, float64, error) {
+	target = strings.Trim(target, "$")
+	alertType := "price"
+	if strings.Contains(target, "%") || strings.HasPrefix(target, "-") {
+		alertType = "percent"
+		target = strings.ReplaceAll(target, "%", "")
+	}
+
+	targetValue, err := strconv.ParseFloat(target, 64)
+	if err != nil {
+		return alertType, 0, errInvalidAlertTarget
+	}
+	return alertType, targetValue, nil
+}
+
+// parseAlertCondition parses the target of an alert on a metric, an empty metric is a price or percent target
+func parseAlertCondition(metric, target string) (string, float64, error) {
+	if metric == "" {
+		return parseAlertTarget(target)
+	}
+
+	target = strings.ToLower(target)
+	switch metric {
+	case "volume":
+		// A percentage is compared with the average volume of the last week
+		if strings.HasSuffix(target, "%") {
+			value, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimPrefix(target, "+"), "%"), 64)
+			if err != nil || value <= 0 {
+				return "", 0, errInvalidAlertTarget
+			}
+			return "volume_percent", value, nil
+		}
+		if value, ok := parseCompactUSD(target); ok {
+			return "volume", value, nil
+		}
+	case "mcap":
+		if value, ok := parseCompactUSD(target); ok {
+			return "mcap", value, nil
+		}
+	case "rank":
+		if value, err := strconv.Atoi(target); err == nil && value > 0 && value <= maxAlertRank {
+			return "rank", float64(value), nil
+		}
+	}
+	return "", 0, errInvalidAlertTarget
+}
+
+// splitAlertMetric splits the metric keyword from the target of a volume, market cap or rank alert: "vol 50b"
+func splitAlertMetric(spec string) (string, string) {
+	fields := strings.Fields(spec)
+	if len(fields) > 1 {
+		if metric, known := alertMetricKeywords[strings.ToLower(fields[0])]; known {
+			return metric, strings.Join(fields[1:], " ")
+		}
+	}
+	return "", spec
+}
+
+// parseCompactUSD parses a positive dollar amount with an optional k, m, b or t suffix such as "50b" or "$1.5t"
+func parseCompactUSD(text string) (float64, bool) {
+	text = strings.Trim(strings.ToLower(text), "$")
+	multiplier := 1.0
+	if len(text) > 0 {
+		if m, found := compactMultipliers[text[len(text)-1]]; found {
+			multiplier, text = m, text[:len(text)-1]
+		}
+	}
+
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil || value <= 0 || math.IsInf(value, 0) {
+		return 0, false
+	}
+	return value * multiplier, true
+}
+
+// alertReference returns the value an alert is compared with when it is set: the current price, volume, market cap
+// or rank, or the average volume of the last week for volume percent alerts
+func alertReference(alertType string, c *coinpaprika.Coin, p price.PriceInfo) (float64, error) {
+	switch alertType {
+	case "volume":
+		return p.Volume24h, nil
+	case "volume_percent":
+		return commands.AverageVolume(c, averageVolumeDays)
+	case "mcap":
+		return p.MarketCap, nil
+	case "rank":
+		if p.Rank <= 0 {
+			return 0, errors.Errorf("%s is not ranked", *c.ID)
+		}
+		return float64(p.Rank), nil
+	}
+	return p.PriceUSD, nil
+}
+
+// formatAlertTarget describes the target of an alert, escaped for MarkdownV2
+func formatAlertTarget(alert types.Alert) string {
+	switch alert.AlertType {
+	case "percent":
+		return helpers.EscapeMarkdownV2(fmt.Sprintf("%.1f%%", alert.Target))
+	case "window":
+		return translation.Translate(
+			"alert_condition_window",
+			helpers.EscapeMarkdownV2(fmt.Sprintf("%+.1f", alert.Target)),
+			helpers.FormatInterval(time.Duration(alert.Window)*time.Second),
+		)
+	case "volume":
+		return translation.Translate("alert_condition_volume", helpers.EscapeMarkdownV2(helpers.FormatCompactUS(alert.Target)))
+	case "volume_percent":
+		return translation.Translate("alert_condition_volume_percent", helpers.EscapeMarkdownV2(strconv.FormatFloat(alert.Target, 'f', -1, 64)))
+	case "mcap":
+		return translation.Translate("alert_condition_mcap", helpers.EscapeMarkdownV2(helpers.FormatCompactUS(alert.Target)))
+	case "rank":
+		// A coin outside the top N when the alert was set fires on entering it, otherwise on leaving it
+		if alert.CurrentPrice > alert.Target {
+			return translation.Translate("alert_condition_rank_enter", int(alert.Target))
+		}
+		return translation.Translate("alert_condition_rank_leave", int(alert.Target))
+	}
+	if alert.QuoteTicker != "" {
+		// The ratio of a pair alert is the price of the coin in the quote coin
+		quote, _ := price.GetPrice(alert.QuoteTicker)
+		return strings.TrimSpace(helpers.FormatPriceUS(alert.Target, true) + " " + helpers.EscapeMarkdownV2(quote.Symbol))
+	}
+	return "$" + helpers.FormatPriceUS(alert.Target, true)
+}
+
+// parseAlertID parses an alert ID as shown by /alert list, with or without the leading #
+func parseAlertID(text string) (int64, bool) {
+	alertID, err := strconv.ParseInt(strings.TrimPrefix(strings.TrimSpace(text), "#"), 10, 64)
+	return alertID, err == nil && alertID > 0
+}
+
+// parseAlertCallback parses the alert ID of "alert_delete|<id>" and "alert_edit|<id>" callback data
+func parseAlertCallback(data string) (int64, bool) {
+	parts := strings.Split(data, "|")
+	if len(parts) != 2 {
+		return 0, false
+	}
+	return parseAlertID(parts[1])
+}
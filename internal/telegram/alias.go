@@ -0,0 +1,90 @@
+package telegram
+
+import (
+	"coinpaprika-telegram-bot/internal/commands"
+	"coinpaprika-telegram-bot/internal/database"
+	"coinpaprika-telegram-bot/lib/helpers"
+	"coinpaprika-telegram-bot/lib/translation"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+	"strings"
+)
+
+// HandleAliasCommand handles /alias set|list|remove, pinning ambiguous tickers to a coin for the chat
+func (b *Bot) HandleAliasCommand(u tgbotapi.Update) string {
+	chatID := u.Message.Chat.ID
+	args := strings.Fields(u.Message.CommandArguments())
+	if len(args) == 0 {
+		return translation.Translate("alias_command_usage")
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "list":
+		aliases, err := database.GetAliasesByChatID(chatID)
+		if err != nil {
+			log.Error(err)
+			return translation.Translate("alias_update_failed")
+		}
+		if len(aliases) == 0 {
+			return translation.Translate("no_aliases")
+		}
+
+		var list strings.Builder
+		list.WriteString(translation.Translate("aliases_list_header"))
+		for _, alias := range aliases {
+			list.WriteString(translation.Translate(
+				"alias_list_item_format",
+				helpers.EscapeMarkdownV2(alias.Alias), alias.CoinID, helpers.EscapeMarkdownV2(alias.CoinID)))
+		}
+		return list.String()
+	case "set":
+		if len(args) != 3 {
+			return translation.Translate("alias_command_usage")
+		}
+		if !b.isChatAdmin(u.Message) {
+			return translation.Translate("admin_only")
+		}
+
+		alias := commands.NormalizeAlias(args[1])
+		if alias == "" {
+			return translation.Translate("alias_command_usage")
+		}
+
+		c, err := commands.GetCoinByID(strings.ToLower(args[2]))
+		if err != nil {
+			log.Error(err)
+			return translation.Translate("alias_invalid_coin", helpers.EscapeMarkdownV2(args[2]))
+		}
+
+		if err := database.SetAlias(chatID, alias, *c.ID); err != nil {
+			log.Error(err)
+			return translation.Translate("alias_update_failed")
+		}
+		return translation.Translate(
+			"alias_set_success",
+			helpers.EscapeMarkdownV2(alias),
+			helpers.EscapeMarkdownV2(fmt.Sprintf("%s (%s)", *c.Name, *c.Symbol)),
+			*c.ID)
+	case "remove":
+		if len(args) != 2 {
+			return translation.Translate("alias_command_usage")
+		}
+		if !b.isChatAdmin(u.Message) {
+			return translation.Translate("admin_only")
+		}
+
+		alias := commands.NormalizeAlias(args[1])
+		removed, err := database.RemoveAlias(chatID, alias)
+		if err != nil {
+			log.Error(err)
+			return translation.Translate("alias_update_failed")
+		}
+		if !removed {
+			return translation.Translate("alias_not_found", helpers.EscapeMarkdownV2(alias))
+		}
+		return translation.Translate("alias_removed", helpers.EscapeMarkdownV2(alias))
+	}
+
+	return translation.Translate("alias_command_usage")
+}
@@ -4,6 +4,7 @@ import (
 	"coinpaprika-telegram-bot/internal/commands"
 	"coinpaprika-telegram-bot/internal/database"
 	"coinpaprika-telegram-bot/internal/price"
+	"coinpaprika-telegram-bot/internal/types"
 	"coinpaprika-telegram-bot/lib/helpers"
 	"coinpaprika-telegram-bot/lib/translation"
 	"fmt"
@@ -12,8 +13,8 @@ import (
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"regexp"
-	"strconv"
 	"strings"
+	"time"
 )
 
 // NewBot creates new telegram bot
@@ -29,6 +30,10 @@ func NewBot(c BotConfig) (*Bot, error) {
 		Bot:              bot,
 		Config:           c,
 		messageTargetMap: make(map[int]string),
+		alertEditMap:     make(map[chatMessage]pendingEdit),
+		alertSpecMap:     make(map[chatMessage]pendingAlert),
+		chartFileIDs:     make(map[string]cachedChart),
+		chartRefreshes:   make(map[chatMessage]time.Time),
 	}, nil
 }
 
@@ -51,6 +56,59 @@ func (b *Bot) SendMessage(m Message) error {
 	return errors.Wrapf(err, "could not send message: %v", m)
 }
 
+// sendChart sends a rendered chart as a photo reply with a MarkdownV2 caption and optional buttons
+func (b *Bot) sendChart(chatID int64, replyTo int, chartData []byte, caption string, keyboard *tgbotapi.InlineKeyboardMarkup) (tgbotapi.Message, error) {
+	photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{
+		Name:  "chart.png",
+		Bytes: chartData,
+	})
+	photo.Caption = caption
+	photo.ParseMode = "MarkdownV2"
+	photo.ReplyToMessageID = replyTo
+	if keyboard != nil {
+		photo.ReplyMarkup = keyboard
+	}
+	m, err := b.Bot.Send(photo)
+	if err != nil {
+		log.Error("error sending chart:", err)
+	}
+	return m, err
+}
+
+// isChatAdmin reports whether the sender of a message may change the chat's settings
+func (b *Bot) isChatAdmin(message *tgbotapi.Message) bool {
+	// Anonymous group admins post on behalf of the chat itself
+	if message.SenderChat != nil && message.SenderChat.ID == message.Chat.ID {
+		return true
+	}
+
+	return b.isChatMemberAdmin(message.Chat, message.From)
+}
+
+// isChatMemberAdmin reports whether a user may change the chat's settings, e.g. the user pressing a button
+func (b *Bot) isChatMemberAdmin(chat *tgbotapi.Chat, user *tgbotapi.User) bool {
+	if chat.IsPrivate() {
+		return true
+	}
+
+	if user == nil {
+		return false
+	}
+
+	member, err := b.Bot.GetChatMember(tgbotapi.GetChatMemberConfig{
+		ChatConfigWithUser: tgbotapi.ChatConfigWithUser{
+			ChatID: chat.ID,
+			UserID: user.ID,
+		},
+	})
+	if err != nil {
+		log.Error("Failed to fetch chat member: ", err)
+		return false
+	}
+
+	return member.IsCreator() || member.IsAdministrator()
+}
+
 func ParseArguments(args string) (string, string) {
 	re := regexp.MustCompile(`^(\S+)\s*(.+)?$`)
 	matches := re.FindStringSubmatch(args)
@@ -75,42 +133,66 @@ func (b *Bot) HandleUpdate(u tgbotapi.Update) string {
 
 	// Handle commands starting with /
 	switch u.Message.Command() {
+	case "start":
+	case "help":
+		text = b.HandleHelpCommand(u)
 	case "source":
 		text = "https://github\\.com/coinpaprika/telegram\\-bot\\-v2"
 	case "p":
-		if text, err = commands.CommandPrice(u.Message.CommandArguments()); err != nil {
-			text = translation.Translate("Coin not found")
+		if text, err = commands.CommandPrice(u.Message.Chat.ID, u.Message.CommandArguments()); err != nil {
+			text = coinNotFoundText(u.Message.CommandArguments())
 			log.Error(err)
 		}
 	case "s":
-		if text, err = commands.CommandSupply(u.Message.CommandArguments()); err != nil {
-			text = translation.Translate("Coin not found")
+		if text, err = commands.CommandSupply(u.Message.Chat.ID, u.Message.CommandArguments()); err != nil {
+			text = coinNotFoundText(u.Message.CommandArguments())
 			log.Error(err)
 		}
 	case "v":
-		if text, err = commands.CommandVolume(u.Message.CommandArguments()); err != nil {
-			text = translation.Translate("Coin not found")
+		if text, err = commands.CommandVolume(u.Message.Chat.ID, u.Message.CommandArguments()); err != nil {
+			text = coinNotFoundText(u.Message.CommandArguments())
 			log.Error(err)
 		}
+	case "h":
+		if text, err = commands.CommandHistory(u.Message.Chat.ID, u.Message.CommandArguments()); err != nil {
+			if err == commands.ErrInvalidDate {
+				text = translation.Translate("history_command_usage")
+			} else {
+				text = translation.Translate("Coin not found")
+				log.Error(err)
+			}
+		}
+	case "roi":
+		if text, err = commands.CommandROI(u.Message.Chat.ID, u.Message.CommandArguments()); err != nil {
+			if err == commands.ErrInvalidROI {
+				text = translation.Translate("roi_command_usage")
+			} else {
+				text = translation.Translate("Coin not found")
+				log.Error(err)
+			}
+		}
+	case "dca":
+		text = b.HandleDCACommand(u)
+	case "stats":
+		text = b.HandleStatsCommand(u)
+	case "corr":
+		text = b.HandleCorrelationCommand(u)
 	case "c":
 		coin, timeRange := ParseArguments(u.Message.CommandArguments())
-		chartData, caption, err := commands.CommandChart(coin, timeRange)
+		c, err := commands.ResolveCoin(u.Message.Chat.ID, coin)
+		if err != nil {
+			text = coinNotFoundText(coin)
+			log.Error(err)
+			break
+		}
+
+		chartData, caption, err := commands.CoinChart(c, timeRange)
 		if err != nil {
 			text = translation.Translate("Coin not found")
 			log.Error(err)
 		} else {
 			if chartData != nil {
-				photo := tgbotapi.NewPhoto(u.Message.Chat.ID, tgbotapi.FileBytes{
-					Name:  "chart.png",
-					Bytes: chartData,
-				})
-				photo.Caption = caption
-				photo.ParseMode = "MarkdownV2"
-				photo.ReplyToMessageID = u.Message.MessageID
-				_, err = b.Bot.Send(photo)
-				if err != nil {
-					log.Error("error sending chart:", err)
-				}
+				b.sendChart(u.Message.Chat.ID, u.Message.MessageID, chartData, caption, chartKeyboard(chartKindPrice, *c.ID, timeRange))
 				return ""
 			} else {
 				text = caption
@@ -118,82 +200,156 @@ func (b *Bot) HandleUpdate(u tgbotapi.Update) string {
 		}
 	case "o":
 		coin, timeRange := ParseArguments(u.Message.CommandArguments())
-		chartData, caption, err := commands.CommandChartWithTicker(coin, timeRange)
+		c, err := commands.ResolveCoin(u.Message.Chat.ID, coin)
 		if err != nil {
-			text = translation.Translate("Coin not found")
+			text = coinNotFoundText(coin)
+			log.Error(err)
+		} else {
+			return b.sendOverview(u, c, timeRange)
+		}
+	case "global":
+		chartData, caption, err := commands.CommandGlobal()
+		if err != nil {
+			text = translation.Translate("Global market data unavailable")
 			log.Error(err)
 		} else {
 			if chartData != nil {
-				photo := tgbotapi.NewPhoto(u.Message.Chat.ID, tgbotapi.FileBytes{
-					Name:  "chart.png",
-					Bytes: chartData,
-				})
-				photo.Caption = caption
-				photo.ParseMode = "MarkdownV2"
-				photo.ReplyToMessageID = u.Message.MessageID
-				_, err = b.Bot.Send(photo)
-				if err != nil {
-					log.Error("error sending chart:", err)
-				}
+				b.sendChart(u.Message.Chat.ID, u.Message.MessageID, chartData, caption, nil)
 				return ""
 			} else {
 				text = caption
 			}
 		}
+	case "events":
+		text = b.HandleEventsCommand(u)
+	case "alias":
+		text = b.HandleAliasCommand(u)
+	case "pf":
+		text = b.HandlePortfolioCommand(u)
+	case "watch":
+		text = b.HandleWatchCommand(u)
+	case "wl":
+		text = b.HandleWatchlistCommand(u)
+	case "digest":
+		text = b.HandleDigestCommand(u)
+	case "export":
+		text = b.HandleExportCommand(u)
+	case "calc":
+		if text, err = commands.CommandCalc(u.Message.Chat.ID, u.Message.CommandArguments()); err != nil {
+			log.Debug(err)
+			text = translation.Translate("calc_command_usage")
+		}
+	case "ca":
+		args := strings.Fields(u.Message.CommandArguments())
+		if len(args) == 2 {
+			return b.handleContractLookup(u, args[1], []string{commands.ResolvePlatform(args[0])})
+		}
+		if len(args) == 1 {
+			if address, platforms, ok := commands.ParseContractAddress(args[0]); ok {
+				return b.handleContractLookup(u, address, platforms)
+			}
+		}
+		text = translation.Translate("ca_command_usage")
 	case "alert":
-		args := u.Message.CommandArguments()
-		if strings.TrimSpace(args) == "list" {
-			text = b.HandleAlertListCommand(u.Message.Chat.ID)
-		} else {
-			return b.HandleAlertCommand(u)
+		return b.HandleAlertCommand(u)
+	default:
+		if u.Message.IsCommand() {
+			text = unknownCommandText(u.Message.Command())
+		}
+	}
+
+	// Handle pasted contract addresses, an address without a coin may just be chat so it is not answered
+	if !u.Message.IsCommand() {
+		if address, platforms, ok := commands.DetectContractAddress(u.Message.Text); ok {
+			c, err := commands.SearchContract(address, platforms)
+			if err != nil {
+				log.Debug(err)
+				return ""
+			}
+			return b.sendOverview(u, c, "")
 		}
 	}
 
+	// Handle several cashtags anywhere in a message
+	cashtags := ExtractCashtags(u.Message)
+	if !u.Message.IsCommand() && len(cashtags) > 1 {
+		return b.handleCashtags(u, cashtags)
+	}
+
 	// Handle $ commands
 	if u.Message.Text != "" && u.Message.Text[0] == '$' {
 		rawArgs := strings.TrimSpace(u.Message.Text[1:])
 		coin, timeRange := ParseArguments(rawArgs)
 
-		chartData, caption, err := commands.CommandChartWithTicker(coin, timeRange)
+		c, err := commands.ResolveCoin(u.Message.Chat.ID, coin)
+		if err != nil {
+			text = coinNotFoundText(coin)
+			log.Error(err)
+		} else {
+			return b.sendOverview(u, c, timeRange)
+		}
+	} else if !u.Message.IsCommand() && len(cashtags) == 1 {
+		c, err := commands.ResolveCoin(u.Message.Chat.ID, cashtags[0])
 		if err != nil {
 			text = translation.Translate("Coin not found")
 			log.Error(err)
 		} else {
-			if chartData != nil {
-				photo := tgbotapi.NewPhoto(u.Message.Chat.ID, tgbotapi.FileBytes{
-					Name:  "chart.png",
-					Bytes: chartData,
-				})
-				photo.Caption = caption
-				photo.ParseMode = "MarkdownV2"
-				photo.ReplyToMessageID = u.Message.MessageID
-				_, err = b.Bot.Send(photo)
-				if err != nil {
-					log.Error("error sending chart:", err)
-				}
-				return ""
-			} else {
-				text = caption
-			}
+			return b.sendOverview(u, c, "")
 		}
 	}
 
 	return text
 }
 
+// handleContractLookup replies with the coin overview of a contract address
+func (b *Bot) handleContractLookup(u tgbotapi.Update, address string, platforms []string) string {
+	c, err := commands.SearchContract(address, platforms)
+	if err != nil {
+		log.Error(err)
+		return translation.Translate("contract_not_found", address)
+	}
+
+	return b.sendOverview(u, c, "")
+}
+
+// sendOverview replies with the coin overview chart and remembers the uploaded photo for inline queries
+func (b *Bot) sendOverview(u tgbotapi.Update, c *coinpaprika.Coin, timeRange string) string {
+	chartData, caption, err := commands.CoinChartWithTicker(c, timeRange)
+	if err != nil {
+		log.Error(err)
+		return translation.Translate("Coin not found")
+	}
+
+	if chartData == nil {
+		return caption
+	}
+
+	keyboard := chartKeyboard(chartKindOverview, *c.ID, timeRange)
+	if m, err := b.sendChart(u.Message.Chat.ID, u.Message.MessageID, chartData, caption, keyboard); err == nil {
+		b.rememberChart(*c.ID, m)
+	}
+	return ""
+}
+
 func (b *Bot) HandleCallbackQuery(callbackQuery *tgbotapi.CallbackQuery) {
 	data := callbackQuery.Data
 	chatID := callbackQuery.Message.Chat.ID
 	messageID := callbackQuery.Message.MessageID // Get the MessageID for deletion
 
 	switch {
+	case strings.HasPrefix(data, "chart|"):
+		b.handleChartCallback(callbackQuery)
+	case strings.HasPrefix(data, "alert_delete|"):
+		b.handleAlertDeleteCallback(callbackQuery)
+	case strings.HasPrefix(data, "alert_edit|"):
+		b.handleAlertEditCallback(callbackQuery)
 	case strings.HasPrefix(data, "alert_select"):
 		parts := strings.Split(data, "|")
-		if len(parts) < 3 {
+		pending, exists := b.takePendingAlert(chatMessage{ChatID: chatID, MessageID: messageID})
+		if len(parts) < 2 || !exists {
 			b.Bot.Send(tgbotapi.NewCallback(callbackQuery.ID, translation.Translate("Invalid alert data.")))
 			return
 		}
-		target := parts[2]
 		ticker, found := price.GetTickerByID(parts[1])
 		if !found {
 			b.Bot.Send(tgbotapi.NewCallback(callbackQuery.ID, translation.Translate("Invalid alert data.")))
@@ -206,7 +362,7 @@ func (b *Bot) HandleCallbackQuery(callbackQuery *tgbotapi.CallbackQuery) {
 			return
 		}
 
-		successMsg, err := b.InsertAlert(chatID, coin, target)
+		successMsg, err := b.InsertAlert(chatID, pending.UserID, coin, nil, pending.Target)
 		if err != nil {
 			b.Bot.Send(tgbotapi.NewCallback(callbackQuery.ID, translation.Translate("Failed to save alert. Please try again later.")))
 			msg := tgbotapi.NewMessage(chatID, err.Error())
@@ -232,14 +388,13 @@ func (b *Bot) HandleCallbackQuery(callbackQuery *tgbotapi.CallbackQuery) {
 		b.Bot.Send(tgbotapi.NewCallback(callbackQuery.ID, translation.Translate("Alert saved successfully.")))
 
 	case strings.HasPrefix(data, "alert_cancel"):
-		parts := strings.Split(data, "|")
-		if len(parts) < 2 {
+		pending, exists := b.takePendingAlert(chatMessage{ChatID: chatID, MessageID: messageID})
+		target := pending.Target
+		if !exists {
 			b.Bot.Send(tgbotapi.NewCallback(callbackQuery.ID, translation.Translate("Invalid alert data.")))
 			return
 		}
 
-		target := parts[1]
-
 		// Delete the options message
 		deleteMsg := tgbotapi.NewDeleteMessage(chatID, messageID)
 		_, err := b.Bot.Request(deleteMsg)
@@ -278,6 +433,14 @@ func (b *Bot) HandleReply(message *tgbotapi.Message) {
 
 	// Check if the referenced message exists in the mapping
 	if message.ReplyToMessage != nil {
+		if alertID, exists := b.pendingEditAlert(chatMessage{ChatID: chatID, MessageID: message.ReplyToMessage.MessageID}); exists {
+			b.SendMessage(Message{
+				ChatID: int(chatID),
+				Text:   b.editAlertFromReply(message, alertID, replyText),
+			})
+			return
+		}
+
 		target, exists := b.messageTargetMap[message.ReplyToMessage.MessageID]
 		if !exists {
 			b.SendMessage(Message{
@@ -309,7 +472,7 @@ func (b *Bot) HandleReply(message *tgbotapi.Message) {
 			return
 		}
 
-		successMsg, err := b.InsertAlert(chatID, coin, target)
+		successMsg, err := b.InsertAlert(chatID, alertCreatorID(message), coin, nil, target)
 		if err != nil {
 			msg := tgbotapi.NewMessage(chatID, err.Error())
 			msg.ParseMode = "MarkdownV2"
@@ -337,16 +500,31 @@ func (b *Bot) HandleReply(message *tgbotapi.Message) {
 func (b *Bot) HandleAlertCommand(u tgbotapi.Update) string {
 	args := u.Message.CommandArguments()
 	ticker, target := ParseArguments(args)
-	if ticker == "list" {
-		b.HandleAlertListCommand(u.Message.Chat.ID)
-		return ""
+	switch ticker {
+	case "list":
+		return b.HandleAlertListCommand(u.Message.Chat.ID)
+	case "delete", "del", "rm", "remove":
+		return b.handleAlertDelete(u, target)
+	case "clear":
+		return b.handleAlertClear(u)
+	case "edit":
+		return b.handleAlertEdit(u, target)
+	}
+
+	if base, quote, isPair := strings.Cut(ticker, "/"); isPair && target != "" {
+		return b.handlePairAlert(u.Message.Chat.ID, alertCreatorID(u.Message), base, quote, target)
 	}
 
 	if ticker == "" || target == "" {
 		return helpers.EscapeMarkdownV2(translation.Translate("alert_command_usage"))
 	}
 
-	coins, err := commands.SearchCoins(ticker)
+	// Options are checked before offering the coins, a mistyped expiry would otherwise only show up after picking one
+	if _, _, _, err := parseAlertSpec(target, time.Now().UTC()); err != nil {
+		return err.Error()
+	}
+
+	coins, err := commands.SearchCoinsForChat(u.Message.Chat.ID, ticker)
 	if err != nil {
 		log.Error(err)
 		return translation.Translate("coin_search_failed")
@@ -367,7 +545,7 @@ func (b *Bot) HandleAlertCommand(u tgbotapi.Update) string {
 		buttons = append(buttons, tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(
 				fmt.Sprintf(translation.Translate("coin_display_format"), *coin.Name, *coin.Symbol),
-				fmt.Sprintf("alert_select|%d|%s", p.ID, target),
+				fmt.Sprintf("alert_select|%d", p.ID),
 			),
 		))
 		counter++
@@ -382,58 +560,102 @@ func (b *Bot) HandleAlertCommand(u tgbotapi.Update) string {
 	buttons = append(buttons, tgbotapi.NewInlineKeyboardRow(
 		tgbotapi.NewInlineKeyboardButtonData(
 			translation.Translate("alert_manual_link_option"),
-			fmt.Sprintf("alert_cancel|%s", target),
+			"alert_cancel",
 		),
 	))
 
 	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(buttons...)
 
-	_, err = b.Bot.Send(msg)
+	// The target with its options can exceed the 64 byte callback data limit, so the buttons only name the coin
+	// and the target waits here until one of them is pressed
+	m, err := b.Bot.Send(msg)
 	if err != nil {
 		log.Error(translation.Translate("coin_selection_buttons_failed"), err)
+		return ""
 	}
+	b.storePendingAlert(chatMessage{ChatID: u.Message.Chat.ID, MessageID: m.MessageID},
+		pendingAlert{Target: target, UserID: alertCreatorID(u.Message)})
 	return ""
 }
 
-// InsertAlert handles alert insertion logic
-func (b *Bot) InsertAlert(chatID int64, coin *coinpaprika.Coin, target string) (string, error) {
-	var alertType string
-	var formattedTarget string
-
+// InsertAlert handles alert insertion logic. The target may name a metric ("vol 50b", "mcap 2t", "rank 10")
+// and be followed by a move window, repeat and expiry options: "5% 1h repeat 30m 2% for 7d".
+// With a quote coin the price and percent targets apply to the ratio of the two prices instead of the USD price.
+func (b *Bot) InsertAlert(chatID, userID int64, coin, quote *coinpaprika.Coin, target string) (string, error) {
 	cp, exists := price.GetPrice(*coin.ID)
 	if !exists {
 		return "", errors.New(translation.Translate("current_price_not_found"))
 	}
 
-	if strings.Contains(target, "%") || strings.HasPrefix(target, "-") {
-		alertType = "percent"
-		target = strings.ReplaceAll(target, "%", "")
+	metric, target, opts, err := parseAlertSpec(target, time.Now().UTC())
+	if err != nil {
+		return "", err
+	}
+	if quote != nil && (metric != "" || opts.Window > 0) {
+		return "", errors.New(translation.Translate("alert_pair_invalid"))
+	}
 
-		targetValue, err := strconv.ParseFloat(target, 64)
-		if err != nil {
-			return "", errors.New(fmt.Sprintf(
-				translation.Translate("invalid_percent_target"),
-				helpers.EscapeMarkdownV2(fmt.Sprintf("%s (%s)", *coin.Name, *coin.Symbol)),
-				*coin.ID,
-				helpers.FormatPriceUS(cp.PriceUSD, true),
-			))
-		}
-		formattedTarget = helpers.EscapeMarkdownV2(fmt.Sprintf("%.1f%%", targetValue))
+	alertType, targetValue, err := parseAlertCondition(metric, target)
+	if err != nil && quote != nil {
+		return "", errors.New(translation.Translate("alert_pair_invalid"))
+	}
+	if err != nil && metric != "" {
+		return "", errors.New(translation.Translate("invalid_metric_target", helpers.EscapeMarkdownV2(target)))
+	}
+	if err != nil {
+		invalidTarget := "invalid_price_target"
+		if alertType == "percent" {
+			invalidTarget = "invalid_percent_target"
+		}
+		return "", errors.New(fmt.Sprintf(
+			translation.Translate(invalidTarget),
+			helpers.EscapeMarkdownV2(fmt.Sprintf("%s (%s)", *coin.Name, *coin.Symbol)),
+			*coin.ID,
+			helpers.FormatPriceUS(cp.PriceUSD, true),
+		))
+	}
+
+	// A window turns a percent target into a move within any trailing window of that length
+	if opts.Window > 0 {
+		if alertType != "percent" {
+			return "", errors.New(translation.Translate("alert_window_invalid"))
+		}
+		alertType = "window"
+	}
+
+	name := fmt.Sprintf("%s (%s)", *coin.Name, *coin.Symbol)
+	var reference float64
+	var quoteTicker string
+	if quote != nil {
+		ratio, found := price.GetRatio(*coin.ID, *quote.ID)
+		if !found {
+			return "", errors.New(translation.Translate("current_price_not_found"))
+		}
+		name = fmt.Sprintf("%s/%s", *coin.Symbol, *quote.Symbol)
+		reference, quoteTicker = ratio, *quote.ID
 	} else {
-		targetValue, err := strconv.ParseFloat(target, 64)
+		reference, err = alertReference(alertType, coin, cp)
 		if err != nil {
-			return "", errors.New(fmt.Sprintf(
-				translation.Translate("invalid_price_target"),
-				helpers.EscapeMarkdownV2(fmt.Sprintf("%s (%s)", *coin.Name, *coin.Symbol)),
-				*coin.ID,
-				helpers.FormatPriceUS(cp.PriceUSD, true),
-			))
+			log.Error(err)
+			return "", errors.New(translation.Translate("alert_reference_unavailable"))
 		}
-		alertType = "price"
-		formattedTarget = "$" + helpers.FormatPriceUS(targetValue, true)
 	}
 
-	err := database.InsertAlert(chatID, *coin.ID, target, alertType, strconv.FormatFloat(cp.PriceUSD, 'f', -1, 64))
+	alert := types.Alert{
+		ChatID:         chatID,
+		UserID:         userID,
+		Ticker:         *coin.ID,
+		Target:         targetValue,
+		AlertType:      alertType,
+		CurrentPrice:   reference,
+		Window:         int64(opts.Window.Seconds()),
+		RepeatInterval: int64(opts.Repeat.Seconds()),
+		Hysteresis:     opts.Hysteresis,
+		QuoteTicker:    quoteTicker,
+		ExpiresAt:      opts.ExpiresAt,
+		NotifyExpiry:   !opts.Silent,
+	}
+	err = database.InsertAlert(alert)
 	if err != nil {
 		log.Error(translation.Translate("alert_save_failed"), err)
 		return "", errors.Wrap(err, translation.Translate("database_insert_failed"))
@@ -441,51 +663,15 @@ func (b *Bot) InsertAlert(chatID int64, coin *coinpaprika.Coin, target string) (
 
 	successMsg := fmt.Sprintf(
 		translation.Translate("alert_set_success"),
-		helpers.EscapeMarkdownV2(fmt.Sprintf("%s (%s)", *coin.Name, *coin.Symbol)),
+		helpers.EscapeMarkdownV2(name),
 		*coin.ID,
-		formattedTarget,
+		formatAlertTarget(alert),
 	)
-	return successMsg, nil
-}
-
-func (b *Bot) HandleAlertListCommand(chatID int64) string {
-	alerts, err := database.GetAlertsByChatID(chatID)
-	if err != nil {
-		log.Error(translation.Translate("error_fetching_alerts"), err)
-		return translation.Translate("fetch_alerts_failed")
+	if opts.Repeat > 0 {
+		successMsg += "\n" + formatAlertRepeat(alert.RepeatInterval, alert.Hysteresis)
 	}
-
-	if len(alerts) == 0 {
-		return translation.Translate("no_active_alerts")
+	if !opts.ExpiresAt.IsZero() {
+		successMsg += "\n" + formatAlertExpiry(opts.ExpiresAt)
 	}
-
-	var alertList strings.Builder
-	alertList.WriteString(translation.Translate("active_alerts_list_header"))
-	for _, alert := range alerts {
-		c, err := commands.GetCoinByID(alert.Ticker)
-		if err != nil {
-			continue
-		}
-
-		var targetString string
-		if alert.AlertType == "percent" {
-			targetString = fmt.Sprintf(translation.Translate("alert_target_percent"), helpers.FormatPercentage(alert.Target))
-		} else if alert.AlertType == "price" {
-			targetString = fmt.Sprintf(translation.Translate("alert_target_price"), helpers.FormatPriceUS(alert.Target, true))
-		} else {
-			targetString = fmt.Sprintf(translation.Translate("alert_target_generic"), helpers.FormatPriceUS(alert.Target, true))
-		}
-
-		formattedDate := helpers.EscapeMarkdownV2(helpers.FormatDate(alert.CreatedAt))
-
-		alertList.WriteString(fmt.Sprintf(
-			translation.Translate("alert_list_item_format"),
-			helpers.EscapeMarkdownV2(*c.Name),
-			helpers.EscapeMarkdownV2(*c.Symbol),
-			targetString,
-			formattedDate,
-		))
-	}
-
-	return alertList.String()
+	return successMsg, nil
 }
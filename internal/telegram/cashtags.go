@@ -0,0 +1,181 @@
+package telegram
+
+import (
+	"coinpaprika-telegram-bot/config"
+	"coinpaprika-telegram-bot/internal/commands"
+	"coinpaprika-telegram-bot/internal/price"
+	"coinpaprika-telegram-bot/lib/helpers"
+	"coinpaprika-telegram-bot/lib/translation"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+)
+
+// maxMediaGroupSize is the Telegram limit of photos in a single media group
+const maxMediaGroupSize = 10
+
+// cashtagRegex matches $xxx tickers anywhere in a message, but not prices such as $100
+var cashtagRegex = regexp.MustCompile(`(?:^|[^\w$])\$([A-Za-z][A-Za-z0-9]{0,11})\b`)
+
+// ExtractCashtags returns the deduplicated, lowercased cashtags of a message in order of appearance
+func ExtractCashtags(message *tgbotapi.Message) []string {
+	if message == nil || message.Text == "" {
+		return nil
+	}
+
+	var cashtags []string
+	seen := make(map[string]bool)
+	add := func(tag string) {
+		tag = commands.NormalizeAlias(tag)
+		if tag == "" || seen[tag] {
+			return
+		}
+		seen[tag] = true
+		cashtags = append(cashtags, tag)
+	}
+
+	// Entity offsets are counted in UTF-16 code units
+	encoded := utf16.Encode([]rune(message.Text))
+	for _, entity := range message.Entities {
+		if entity.Type != "cashtag" || entity.Offset < 0 || entity.Offset+entity.Length > len(encoded) {
+			continue
+		}
+		add(string(utf16.Decode(encoded[entity.Offset : entity.Offset+entity.Length])))
+	}
+
+	for _, match := range cashtagRegex.FindAllStringSubmatch(message.Text, -1) {
+		add(match[1])
+	}
+
+	return cashtags
+}
+
+// handleCashtags replies to a message mentioning several cashtags with a price table or a media group of charts
+func (b *Bot) handleCashtags(u tgbotapi.Update, cashtags []string) string {
+	limit := config.GetInt("max_cashtags")
+	if limit <= 0 {
+		limit = 1
+	}
+	if len(cashtags) > limit {
+		cashtags = cashtags[:limit]
+	}
+
+	chatID := u.Message.Chat.ID
+	var coinIDs, notFound []string
+	seen := make(map[string]bool)
+	for _, tag := range cashtags {
+		c, err := commands.ResolveCoin(chatID, tag)
+		if err != nil {
+			log.Debugf("cashtag $%s not resolved: %v", tag, err)
+			notFound = append(notFound, helpers.EscapeMarkdownV2("$"+tag))
+			continue
+		}
+		if !seen[*c.ID] {
+			seen[*c.ID] = true
+			coinIDs = append(coinIDs, *c.ID)
+		}
+	}
+
+	if len(coinIDs) == 0 {
+		return translation.Translate("Coin not found")
+	}
+
+	if strings.ToLower(config.GetString("cashtags_mode")) == "charts" && len(coinIDs) > 1 {
+		if b.sendChartsGroup(u, coinIDs) {
+			return ""
+		}
+	}
+
+	return formatPriceTable(coinIDs, notFound)
+}
+
+// formatPriceTable renders the cached prices of the coins as a compact monospace table
+func formatPriceTable(coinIDs []string, notFound []string) string {
+	var rows [][3]string
+	widths := [3]int{}
+	for _, coinID := range coinIDs {
+		p, found := price.GetPrice(coinID)
+		if !found {
+			notFound = append(notFound, helpers.EscapeMarkdownV2(coinID))
+			continue
+		}
+
+		row := [3]string{
+			p.Symbol,
+			"$" + helpers.FormatPriceUS(p.PriceUSD, false),
+			fmt.Sprintf("%+.2f%%", p.PriceChange24h),
+		}
+		for i, cell := range row {
+			if len(cell) > widths[i] {
+				widths[i] = len(cell)
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	var text strings.Builder
+	if len(rows) > 0 {
+		text.WriteString(translation.Translate("cashtags_table_header"))
+		text.WriteString("```\n")
+		for _, row := range rows {
+			// Prices and changes are right aligned so the decimal points line up
+			text.WriteString(fmt.Sprintf("%-*s %*s %*s\n", widths[0], row[0], widths[1], row[1], widths[2], row[2]))
+		}
+		text.WriteString("```")
+	}
+
+	if len(notFound) > 0 {
+		if text.Len() > 0 {
+			text.WriteString("\n")
+		}
+		text.WriteString(translation.Translate("cashtags_not_found", strings.Join(notFound, ", ")))
+	}
+
+	return text.String()
+}
+
+// sendChartsGroup replies with the overview charts of the coins as a single media group
+func (b *Bot) sendChartsGroup(u tgbotapi.Update, coinIDs []string) bool {
+	var media []interface{}
+	for _, coinID := range coinIDs {
+		if len(media) >= maxMediaGroupSize {
+			break
+		}
+
+		c, err := commands.GetCoinByID(coinID)
+		if err != nil {
+			log.Error(err)
+			continue
+		}
+
+		chartData, caption, err := commands.CoinChartWithTicker(c, "")
+		if err != nil || chartData == nil {
+			log.Debugf("skipping chart of %s in media group: %v", coinID, err)
+			continue
+		}
+
+		photo := tgbotapi.NewInputMediaPhoto(tgbotapi.FileBytes{
+			Name:  coinID + ".png",
+			Bytes: chartData,
+		})
+		photo.Caption = caption
+		photo.ParseMode = "MarkdownV2"
+		media = append(media, photo)
+	}
+
+	// Media groups need at least two items
+	if len(media) < 2 {
+		return false
+	}
+
+	group := tgbotapi.NewMediaGroup(u.Message.Chat.ID, media)
+	group.ReplyToMessageID = u.Message.MessageID
+	if _, err := b.Bot.SendMediaGroup(group); err != nil {
+		log.Error("error sending media group:", err)
+		return false
+	}
+	return true
+}
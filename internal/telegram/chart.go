@@ -0,0 +1,140 @@
+package telegram
+
+import (
+	"coinpaprika-telegram-bot/internal/commands"
+	"coinpaprika-telegram-bot/lib/translation"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+	"strings"
+	"time"
+)
+
+const (
+	chartKindPrice    = "c"
+	chartKindOverview = "o"
+
+	// maxCallbackDataLength is the Telegram limit of inline button callback data in bytes
+	maxCallbackDataLength = 64
+
+	// chartRefreshCooldown is the least time between two refreshes of the same chart message
+	chartRefreshCooldown = 30 * time.Second
+)
+
+// chartButtonRanges are the time ranges offered below every chart
+var chartButtonRanges = []string{"4h", "24h", "7d", "30d"}
+
+// chartKeyboard builds the time range and refresh buttons of a chart message
+func chartKeyboard(kind, coinID, timeRange string) *tgbotapi.InlineKeyboardMarkup {
+	timeRange = commands.NormalizeTimeRange(timeRange)
+
+	var row []tgbotapi.InlineKeyboardButton
+	for _, r := range chartButtonRanges {
+		label := r
+		if r == timeRange {
+			label = "• " + r + " •"
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(label, chartCallbackData(kind, coinID, r)))
+	}
+	row = append(row, tgbotapi.NewInlineKeyboardButtonData(
+		translation.Translate("chart_refresh_button"),
+		chartCallbackData(kind, coinID, timeRange+"|refresh"),
+	))
+
+	// Coins with very long IDs do not fit into the callback data and get a plain chart
+	for _, button := range row {
+		if len(*button.CallbackData) > maxCallbackDataLength {
+			return nil
+		}
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(row)
+	return &keyboard
+}
+
+func chartCallbackData(kind, coinID, timeRange string) string {
+	return fmt.Sprintf("chart|%s|%s|%s", kind, coinID, timeRange)
+}
+
+// handleChartCallback re-renders a chart for the selected time range and replaces the photo in place
+func (b *Bot) handleChartCallback(callbackQuery *tgbotapi.CallbackQuery) {
+	parts := strings.Split(callbackQuery.Data, "|")
+	if len(parts) < 4 || callbackQuery.Message == nil {
+		b.Bot.Send(tgbotapi.NewCallback(callbackQuery.ID, translation.Translate("chart_update_failed")))
+		return
+	}
+	kind, coinID, timeRange := parts[1], parts[2], parts[3]
+	refresh := len(parts) > 4 && parts[4] == "refresh"
+
+	// Every refresh renders the chart again, so repeated presses on one message are throttled
+	if refresh && !b.allowChartRefresh(chatMessage{ChatID: callbackQuery.Message.Chat.ID, MessageID: callbackQuery.Message.MessageID}) {
+		b.Bot.Send(tgbotapi.NewCallback(callbackQuery.ID, translation.Translate("chart_refresh_wait")))
+		return
+	}
+
+	c, err := commands.GetCoinByID(coinID)
+	if err != nil {
+		log.Error(err)
+		b.Bot.Send(tgbotapi.NewCallback(callbackQuery.ID, translation.Translate("chart_update_failed")))
+		return
+	}
+
+	if refresh {
+		commands.ForgetCoinCharts(coinID, timeRange)
+	}
+
+	var chartData []byte
+	var caption string
+	if kind == chartKindOverview {
+		chartData, caption, err = commands.CoinChartWithTicker(c, timeRange)
+	} else {
+		chartData, caption, err = commands.CoinChart(c, timeRange)
+	}
+	if err != nil || chartData == nil {
+		log.Debugf("unable to re-render %s chart of %s: %v", kind, coinID, err)
+		b.Bot.Send(tgbotapi.NewCallback(callbackQuery.ID, translation.Translate("chart_update_failed")))
+		return
+	}
+
+	photo := tgbotapi.NewInputMediaPhoto(tgbotapi.FileBytes{
+		Name:  "chart.png",
+		Bytes: chartData,
+	})
+	photo.Caption = caption
+	photo.ParseMode = "MarkdownV2"
+
+	m, err := b.Bot.Send(tgbotapi.EditMessageMediaConfig{
+		BaseEdit: tgbotapi.BaseEdit{
+			ChatID:      callbackQuery.Message.Chat.ID,
+			MessageID:   callbackQuery.Message.MessageID,
+			ReplyMarkup: chartKeyboard(kind, coinID, timeRange),
+		},
+		Media: photo,
+	})
+	if err != nil {
+		log.Error("Failed to update chart message: ", err)
+		b.Bot.Send(tgbotapi.NewCallback(callbackQuery.ID, translation.Translate("chart_update_failed")))
+		return
+	}
+
+	if kind == chartKindOverview {
+		b.rememberChart(coinID, m)
+	}
+	b.Bot.Send(tgbotapi.NewCallback(callbackQuery.ID, ""))
+}
+
+// allowChartRefresh records a refresh of a chart message unless it was refreshed within the cooldown
+func (b *Bot) allowChartRefresh(key chatMessage) bool {
+	now := time.Now()
+	if last, found := b.chartRefreshes[key]; found && now.Sub(last) < chartRefreshCooldown {
+		return false
+	}
+
+	for k, last := range b.chartRefreshes {
+		if now.Sub(last) >= chartRefreshCooldown {
+			delete(b.chartRefreshes, k)
+		}
+	}
+	b.chartRefreshes[key] = now
+	return true
+}
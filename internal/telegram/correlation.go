@@ -0,0 +1,30 @@
+package telegram
+
+import (
+	"coinpaprika-telegram-bot/internal/commands"
+	"coinpaprika-telegram-bot/lib/translation"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+// HandleCorrelationCommand handles /corr, replying with the correlation matrix of the daily returns of several coins
+func (b *Bot) HandleCorrelationCommand(u tgbotapi.Update) string {
+	chatID := u.Message.Chat.ID
+
+	// Every coin takes its own API request, let the chat know a chart is on its way
+	b.Bot.Send(tgbotapi.NewChatAction(chatID, tgbotapi.ChatUploadPhoto))
+
+	chartData, caption, err := commands.CommandCorrelation(chatID, u.Message.CommandArguments())
+	switch {
+	case err == commands.ErrInvalidCorrelation:
+		return translation.Translate("corr_command_usage", commands.MaxCorrelationCoins)
+	case err == commands.ErrNoCorrelationData:
+		return translation.Translate("corr_no_data")
+	case err != nil:
+		log.Error(err)
+		return translation.Translate("Coin not found")
+	}
+
+	b.sendChart(chatID, u.Message.MessageID, chartData, caption, nil)
+	return ""
+}
@@ -0,0 +1,30 @@
+package telegram
+
+import (
+	"coinpaprika-telegram-bot/internal/commands"
+	"coinpaprika-telegram-bot/lib/translation"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+// HandleDCACommand handles /dca, replying with the equity curve of a simulated dollar-cost averaging plan
+func (b *Bot) HandleDCACommand(u tgbotapi.Update) string {
+	chatID := u.Message.Chat.ID
+
+	// Long plans take several API requests, let the chat know a chart is on its way
+	b.Bot.Send(tgbotapi.NewChatAction(chatID, tgbotapi.ChatUploadPhoto))
+
+	chartData, caption, err := commands.CommandDCA(chatID, u.Message.CommandArguments())
+	switch {
+	case err == commands.ErrInvalidDCA:
+		return translation.Translate("dca_command_usage")
+	case err == commands.ErrNoDCAData:
+		return translation.Translate("dca_no_data")
+	case err != nil:
+		log.Error(err)
+		return translation.Translate("Coin not found")
+	}
+
+	b.sendChart(chatID, u.Message.MessageID, chartData, caption, nil)
+	return ""
+}
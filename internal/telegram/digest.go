@@ -0,0 +1,138 @@
+package telegram
+
+import (
+	"coinpaprika-telegram-bot/config"
+	"coinpaprika-telegram-bot/internal/commands"
+	"coinpaprika-telegram-bot/internal/database"
+	"coinpaprika-telegram-bot/internal/scheduler"
+	"coinpaprika-telegram-bot/internal/types"
+	"coinpaprika-telegram-bot/lib/helpers"
+	"coinpaprika-telegram-bot/lib/translation"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+	"strings"
+	"time"
+)
+
+// digestTimeLayout formats the next run of a digest in the chat's timezone
+const digestTimeLayout = "2006-01-02 15:04"
+
+// HandleDigestCommand handles /digest, which shows, schedules, previews or turns off the chat's market digest
+func (b *Bot) HandleDigestCommand(u tgbotapi.Update) string {
+	chatID := u.Message.Chat.ID
+	args := strings.Fields(u.Message.CommandArguments())
+	if len(args) == 0 {
+		return digestStatus(chatID)
+	}
+
+	if !b.isChatAdmin(u.Message) {
+		return translation.Translate("admin_only")
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "off":
+		removed, err := database.DeleteDigest(chatID)
+		if err != nil {
+			log.Error(err)
+			return translation.Translate("digest_update_failed")
+		}
+		if !removed {
+			return translation.Translate("digest_not_scheduled")
+		}
+		return translation.Translate("digest_disabled")
+	case "now":
+		if err := b.SendDigest(chatID); err != nil {
+			log.Error(err)
+			return translation.Translate("digest_update_failed")
+		}
+		return ""
+	}
+
+	schedule, err := scheduler.Parse(args, defaultDigestLocation())
+	if err != nil {
+		log.Debugf("invalid digest schedule %v: %v", args, err)
+		return translation.Translate("digest_command_usage")
+	}
+
+	next := schedule.Next(time.Now())
+	err = database.SetDigest(types.Digest{
+		ChatID:    chatID,
+		Frequency: schedule.Frequency,
+		TimeOfDay: schedule.Clock(),
+		Timezone:  schedule.Location.String(),
+		NextRunAt: next,
+	})
+	if err != nil {
+		log.Error(err)
+		return translation.Translate("digest_update_failed")
+	}
+
+	return translation.Translate(
+		"digest_scheduled",
+		describeSchedule(schedule),
+		helpers.EscapeMarkdownV2(next.In(schedule.Location).Format(digestTimeLayout)))
+}
+
+// SendDigest posts the market digest of a chat followed by the global market chart
+func (b *Bot) SendDigest(chatID int64) error {
+	err := b.SendMessage(Message{
+		ChatID: int(chatID),
+		Text:   commands.CommandDigest(chatID),
+	})
+	if err != nil {
+		return err
+	}
+
+	chartData, caption, err := commands.CommandGlobal()
+	if err != nil {
+		// The digest text is already out, a missing chart is not worth failing it
+		log.Error(err)
+		return nil
+	}
+	if chartData != nil {
+		b.sendChart(chatID, 0, chartData, caption, nil)
+	}
+	return nil
+}
+
+// digestStatus describes the chat's digest schedule and when it runs next
+func digestStatus(chatID int64) string {
+	digest, err := database.GetDigest(chatID)
+	if err != nil {
+		log.Error(err)
+		return translation.Translate("digest_update_failed")
+	}
+	if digest == nil {
+		return translation.Translate("digest_not_scheduled")
+	}
+
+	schedule, err := scheduler.New(digest.Frequency, digest.TimeOfDay, digest.Timezone)
+	if err != nil {
+		log.Error(err)
+		return translation.Translate("digest_update_failed")
+	}
+
+	return translation.Translate(
+		"digest_status",
+		describeSchedule(schedule),
+		helpers.EscapeMarkdownV2(digest.NextRunAt.In(schedule.Location).Format(digestTimeLayout)))
+}
+
+func describeSchedule(schedule scheduler.Schedule) string {
+	timezone := helpers.EscapeMarkdownV2(schedule.Location.String())
+	if schedule.Frequency == scheduler.Hourly {
+		return translation.Translate("digest_schedule_hourly", fmt.Sprintf("%02d", schedule.Minute), timezone)
+	}
+	return translation.Translate("digest_schedule_daily", schedule.Clock(), timezone)
+}
+
+// defaultDigestLocation is the timezone of schedules given without one
+func defaultDigestLocation() *time.Location {
+	location, err := time.LoadLocation(config.GetString("digest_timezone"))
+	if err != nil {
+		log.Errorf("invalid DIGEST_TIMEZONE: %v", err)
+		return time.UTC
+	}
+	return location
+}
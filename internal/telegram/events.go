@@ -0,0 +1,75 @@
+package telegram
+
+import (
+	"coinpaprika-telegram-bot/internal/commands"
+	"coinpaprika-telegram-bot/internal/database"
+	"coinpaprika-telegram-bot/internal/price"
+	"coinpaprika-telegram-bot/lib/helpers"
+	"coinpaprika-telegram-bot/lib/translation"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+	"strings"
+)
+
+// HandleEventsCommand handles the /events command and its subcommands
+func (b *Bot) HandleEventsCommand(u tgbotapi.Update) string {
+	chatID := u.Message.Chat.ID
+	args := strings.Fields(u.Message.CommandArguments())
+	if len(args) == 0 {
+		return translation.Translate("events_command_usage")
+	}
+
+	subcommand := strings.ToLower(args[0])
+	switch subcommand {
+	case "on", "off", "watch", "unwatch":
+		if !b.isChatAdmin(u.Message) {
+			return translation.Translate("admin_only")
+		}
+	}
+
+	switch subcommand {
+	case "on":
+		if err := database.EnableEventNotifications(chatID); err != nil {
+			log.Error(err)
+			return translation.Translate("events_settings_failed")
+		}
+		return translation.Translate("events_notifications_enabled", formatWatchedCoins(chatID))
+	case "off":
+		if err := database.DisableEventNotifications(chatID); err != nil {
+			log.Error(err)
+			return translation.Translate("events_settings_failed")
+		}
+		return translation.Translate("events_notifications_disabled")
+	case "watch", "unwatch":
+		// The events notifications follow the chat's watchlist, so these are shortcuts of /watch add and /watch remove
+		if len(args) < 2 {
+			return translation.Translate("events_command_usage")
+		}
+		return updateWatchlist(chatID, subcommand == "watch", args[1:])
+	}
+
+	text, err := commands.CommandEvents(chatID, u.Message.CommandArguments())
+	if err != nil {
+		log.Error(err)
+		return translation.Translate("Coin not found")
+	}
+	return text
+}
+
+// formatWatchedCoins returns the chat's watched coin symbols as a MarkdownV2 list
+func formatWatchedCoins(chatID int64) string {
+	coinIDs, err := database.GetWatchlist(chatID)
+	if err != nil || len(coinIDs) == 0 {
+		return translation.Translate("events_no_watched_coins")
+	}
+
+	var symbols []string
+	for _, coinID := range coinIDs {
+		symbol := coinID
+		if p, found := price.GetPrice(coinID); found {
+			symbol = p.Symbol
+		}
+		symbols = append(symbols, helpers.EscapeMarkdownV2(symbol))
+	}
+	return strings.Join(symbols, ", ")
+}
@@ -0,0 +1,40 @@
+package telegram
+
+import (
+	"coinpaprika-telegram-bot/internal/commands"
+	"coinpaprika-telegram-bot/lib/translation"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+// HandleExportCommand handles /export, replying with the historical data of a coin as a document
+func (b *Bot) HandleExportCommand(u tgbotapi.Update) string {
+	chatID := u.Message.Chat.ID
+
+	// Large ranges take several API requests, let the chat know a file is on its way
+	b.Bot.Send(tgbotapi.NewChatAction(chatID, tgbotapi.ChatUploadDocument))
+
+	file, err := commands.CommandExport(chatID, u.Message.CommandArguments())
+	switch {
+	case err == commands.ErrInvalidExport:
+		return translation.Translate("export_command_usage")
+	case err == commands.ErrNoExportData:
+		return translation.Translate("export_no_data")
+	case err != nil:
+		log.Error(err)
+		return translation.Translate("Coin not found")
+	}
+
+	document := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{
+		Name:  file.Name,
+		Bytes: file.Data,
+	})
+	document.Caption = file.Caption
+	document.ParseMode = "MarkdownV2"
+	document.ReplyToMessageID = u.Message.MessageID
+	if _, err := b.Bot.Send(document); err != nil {
+		log.Error("error sending export:", err)
+		return translation.Translate("export_failed")
+	}
+	return ""
+}
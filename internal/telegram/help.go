@@ -0,0 +1,151 @@
+package telegram
+
+import (
+	"coinpaprika-telegram-bot/internal/commands"
+	"coinpaprika-telegram-bot/lib/helpers"
+	"coinpaprika-telegram-bot/lib/translation"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+	"strings"
+)
+
+// menuCommands are registered with Telegram in this order, each has a
+// command_<name>_description and, apart from help, a command_<name>_help catalog entry
+var menuCommands = []string{"o", "p", "s", "v", "c", "h", "roi", "dca", "stats", "corr", "calc", "export", "global", "events", "watch", "wl", "digest", "ca", "alias", "pf", "alert", "help", "source"}
+
+// RegisterCommands publishes the localized command menu for every language of the locales catalogs
+func (b *Bot) RegisterCommands() {
+	// The configured language is the default for clients using any other language
+	configs := []tgbotapi.SetMyCommandsConfig{tgbotapi.NewSetMyCommands(botCommands(translation.GetLanguage())...)}
+	for _, lang := range translation.GetLanguages() {
+		configs = append(configs, tgbotapi.NewSetMyCommandsWithScopeAndLanguage(
+			tgbotapi.NewBotCommandScopeDefault(), lang, botCommands(lang)...))
+	}
+
+	for _, c := range configs {
+		if _, err := b.Bot.Request(c); err != nil {
+			log.Errorf("❌ Failed to register commands for language '%s': %v", c.LanguageCode, err)
+		}
+	}
+	log.Println("✅ Bot commands registered.")
+}
+
+func botCommands(lang string) []tgbotapi.BotCommand {
+	commands := make([]tgbotapi.BotCommand, 0, len(menuCommands))
+	for _, name := range menuCommands {
+		commands = append(commands, tgbotapi.BotCommand{
+			Command:     name,
+			Description: translation.TranslateIn(lang, "command_"+name+"_description"),
+		})
+	}
+	return commands
+}
+
+// HandleHelpCommand returns the command list, or the detailed usage of a single command for /help <command>
+func (b *Bot) HandleHelpCommand(u tgbotapi.Update) string {
+	name := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(u.Message.CommandArguments()), "/"))
+	if name == "" || name == "help" || name == "start" {
+		return translation.Translate("Command help message")
+	}
+
+	if isMenuCommand(name) {
+		return translation.Translate("command_" + name + "_help")
+	}
+
+	if suggestion, found := suggestCommand(name); found && suggestion != "help" {
+		return translation.Translate("command_" + suggestion + "_help")
+	}
+
+	return translation.Translate("help_unknown_command", helpers.EscapeMarkdownV2(name))
+}
+
+// unknownCommandText suggests the closest known command for a mistyped one
+func unknownCommandText(name string) string {
+	if suggestion, found := suggestCommand(name); found {
+		return translation.Translate("did_you_mean", helpers.EscapeMarkdownV2(name), helpers.EscapeMarkdownV2(suggestion))
+	}
+	return translation.Translate("Command help message")
+}
+
+// maxCoinSuggestions is the number of similar coins offered when a coin is not found
+const maxCoinSuggestions = 3
+
+// coinNotFoundText explains that a coin is unknown, suggesting similar coins of the index instead of guessing one
+func coinNotFoundText(query string) string {
+	text := translation.Translate("Coin not found")
+
+	var suggestions []string
+	for _, c := range commands.SuggestCoins(query, maxCoinSuggestions) {
+		suggestions = append(suggestions, helpers.EscapeMarkdownV2(fmt.Sprintf(translation.Translate("coin_display_format"), *c.Name, *c.Symbol)))
+	}
+	if len(suggestions) > 0 {
+		text += "\n\n" + translation.Translate("coin_did_you_mean", strings.Join(suggestions, ", "))
+	}
+	return text
+}
+
+// commandSynonyms maps spelled out names to the short commands, so /price suggests /p
+var commandSynonyms = map[string]string{
+	"overview":    "o",
+	"price":       "p",
+	"supply":      "s",
+	"volume":      "v",
+	"chart":       "c",
+	"history":     "h",
+	"calculate":   "calc",
+	"portfolio":   "pf",
+	"watchlist":   "wl",
+	"volatility":  "stats",
+	"correlation": "corr",
+	"event":       "events",
+	"alerts":      "alert",
+	"contract":    "ca",
+	"start":       "help",
+}
+
+// suggestCommand returns the known command closest to name, tolerating one typo in short and two in longer names
+func suggestCommand(name string) (string, bool) {
+	name = strings.ToLower(name)
+	if command, found := commandSynonyms[name]; found {
+		return command, true
+	}
+
+	maxDistance := 1
+	if len(name) > 4 {
+		maxDistance = 2
+	}
+
+	candidates := make(map[string]string)
+	for _, command := range menuCommands {
+		// Single letter commands are too ambiguous to be matched by edit distance
+		if len(command) > 1 {
+			candidates[command] = command
+		}
+	}
+	for synonym, command := range commandSynonyms {
+		candidates[synonym] = command
+	}
+
+	best, bestDistance := "", maxDistance+1
+	for candidate, command := range candidates {
+		distance := helpers.Levenshtein(name, candidate)
+		if distance < bestDistance || (distance == bestDistance && command < best) {
+			best, bestDistance = command, distance
+		}
+	}
+
+	if best == "" {
+		return "", false
+	}
+	return best, true
+}
+
+func isMenuCommand(name string) bool {
+	for _, command := range menuCommands {
+		if command == name {
+			return true
+		}
+	}
+	return false
+}
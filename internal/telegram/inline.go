@@ -0,0 +1,106 @@
+package telegram
+
+import (
+	"coinpaprika-telegram-bot/internal/commands"
+	"coinpaprika-telegram-bot/internal/price"
+	"coinpaprika-telegram-bot/lib/helpers"
+	"coinpaprika-telegram-bot/lib/translation"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+	"strings"
+	"time"
+)
+
+const (
+	maxInlineResults    = 5
+	inlineChartLifetime = 1 * time.Hour
+	inlineCacheTime     = 30
+)
+
+// HandleInlineQuery answers inline queries (@bot btc) with price summaries and cached overview charts
+func (b *Bot) HandleInlineQuery(q *tgbotapi.InlineQuery) {
+	query := strings.TrimSpace(q.Query)
+	results := make([]interface{}, 0)
+
+	if query != "" {
+		coins, err := commands.SearchCoins(query)
+		if err != nil {
+			log.Debugf("no inline results for '%s': %v", query, err)
+		}
+
+		counter := 0
+		for _, c := range coins {
+			if counter >= maxInlineResults {
+				break
+			}
+
+			p, found := price.GetPrice(*c.ID)
+			if !found {
+				continue
+			}
+
+			summary, ok := commands.PriceSummary(*c.ID)
+			if !ok {
+				continue
+			}
+
+			article := tgbotapi.NewInlineQueryResultArticleMarkdownV2(
+				"p|"+*c.ID,
+				fmt.Sprintf(translation.Translate("coin_display_format"), p.Name, p.Symbol),
+				summary,
+			)
+			article.Description = translation.Translate(
+				"inline_price_description",
+				helpers.FormatPriceUS(p.PriceUSD, false),
+				fmt.Sprintf("%.2f", p.PriceChange24h),
+			)
+			results = append(results, article)
+
+			if fileID, found := b.cachedChartFileID(*c.ID); found {
+				photo := tgbotapi.NewInlineQueryResultCachedPhoto("c|"+*c.ID, fileID)
+				photo.Title = fmt.Sprintf(translation.Translate("coin_display_format"), p.Name, p.Symbol)
+				photo.Caption = summary
+				photo.ParseMode = "MarkdownV2"
+				results = append(results, photo)
+			}
+			counter++
+		}
+	}
+
+	_, err := b.Bot.Request(tgbotapi.InlineConfig{
+		InlineQueryID: q.ID,
+		Results:       results,
+		CacheTime:     inlineCacheTime,
+	})
+	if err != nil {
+		log.Error("Failed to answer inline query: ", err)
+	}
+}
+
+// rememberChart stores the file ID of an uploaded overview chart so inline queries can re-use it
+func (b *Bot) rememberChart(coinID string, m tgbotapi.Message) {
+	if len(m.Photo) == 0 {
+		return
+	}
+
+	b.chartFileIDsMutex.Lock()
+	defer b.chartFileIDsMutex.Unlock()
+
+	b.chartFileIDs[coinID] = cachedChart{
+		FileID:     m.Photo[len(m.Photo)-1].FileID,
+		UploadedAt: time.Now(),
+	}
+}
+
+// cachedChartFileID returns the file ID of a recently uploaded overview chart
+func (b *Bot) cachedChartFileID(coinID string) (string, bool) {
+	b.chartFileIDsMutex.RLock()
+	defer b.chartFileIDsMutex.RUnlock()
+
+	cached, found := b.chartFileIDs[coinID]
+	if !found || time.Since(cached.UploadedAt) > inlineChartLifetime {
+		return "", false
+	}
+	return cached.FileID, true
+}
@@ -0,0 +1,190 @@
+package telegram
+
+import (
+	"coinpaprika-telegram-bot/internal/commands"
+	"coinpaprika-telegram-bot/internal/database"
+	"coinpaprika-telegram-bot/internal/price"
+	"coinpaprika-telegram-bot/lib/helpers"
+	"coinpaprika-telegram-bot/lib/translation"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+	"strconv"
+	"strings"
+)
+
+// HandlePortfolioCommand handles the /pf command and its subcommands.
+// Holdings are only shown in private chats, groups get an allocation summary with /pf share.
+func (b *Bot) HandlePortfolioCommand(u tgbotapi.Update) string {
+	if u.Message.From == nil {
+		return translation.Translate("pf_private_only")
+	}
+	userID := u.Message.From.ID
+	args := strings.Fields(u.Message.CommandArguments())
+
+	subcommand := ""
+	if len(args) > 0 {
+		subcommand = strings.ToLower(args[0])
+	}
+
+	if subcommand == "share" {
+		return b.portfolioSummary(u)
+	}
+
+	if !u.Message.Chat.IsPrivate() {
+		return translation.Translate("pf_private_only")
+	}
+
+	switch subcommand {
+	case "":
+		positions, err := database.GetPortfolio(userID)
+		if err != nil {
+			log.Error(err)
+			return translation.Translate("pf_update_failed")
+		}
+		if len(positions) == 0 {
+			return translation.Translate("pf_empty")
+		}
+		return commands.FormatPortfolio(commands.ValuePortfolio(positions))
+	case "add":
+		return handlePortfolioAdd(u.Message.Chat.ID, userID, args[1:])
+	case "remove", "rm", "sell":
+		return handlePortfolioRemove(u.Message.Chat.ID, userID, args[1:])
+	case "chart":
+		return b.handlePortfolioChart(u, args[1:])
+	}
+
+	return translation.Translate("pf_command_usage")
+}
+
+// handlePortfolioAdd parses "<symbol> <amount> [@ <price>]", buying at the current price when none is given
+func handlePortfolioAdd(chatID, userID int64, args []string) string {
+	var values []string
+	for _, arg := range args {
+		if arg = strings.TrimPrefix(strings.TrimPrefix(arg, "@"), "$"); arg != "" {
+			values = append(values, arg)
+		}
+	}
+	if len(values) < 2 || len(values) > 3 {
+		return translation.Translate("pf_command_usage")
+	}
+
+	amount, err := strconv.ParseFloat(values[1], 64)
+	if err != nil || amount <= 0 {
+		return translation.Translate("pf_invalid_amount")
+	}
+
+	c, err := commands.ResolveCoin(chatID, values[0])
+	if err != nil {
+		log.Error(err)
+		return translation.Translate("Coin not found")
+	}
+
+	var buyPrice float64
+	if len(values) == 3 {
+		buyPrice, err = strconv.ParseFloat(values[2], 64)
+		if err != nil || buyPrice < 0 {
+			return translation.Translate("pf_invalid_amount")
+		}
+	} else {
+		p, found := price.GetPrice(*c.ID)
+		if !found {
+			return translation.Translate("pf_price_unavailable")
+		}
+		buyPrice = p.PriceUSD
+	}
+
+	if err := database.AddPosition(userID, *c.ID, amount, buyPrice); err != nil {
+		log.Error(err)
+		return translation.Translate("pf_update_failed")
+	}
+
+	return translation.Translate(
+		"pf_added",
+		commands.FormatAmount(amount), helpers.EscapeMarkdownV2(*c.Symbol), helpers.FormatPriceUS(buyPrice, true))
+}
+
+// handlePortfolioRemove parses "<symbol> [amount]", removing the whole position when no amount is given
+func handlePortfolioRemove(chatID, userID int64, args []string) string {
+	if len(args) < 1 || len(args) > 2 {
+		return translation.Translate("pf_command_usage")
+	}
+
+	c, err := commands.ResolveCoin(chatID, args[0])
+	if err != nil {
+		log.Error(err)
+		return translation.Translate("Coin not found")
+	}
+	symbol := helpers.EscapeMarkdownV2(*c.Symbol)
+
+	if len(args) == 1 {
+		removed, err := database.RemovePosition(userID, *c.ID)
+		if err != nil {
+			log.Error(err)
+			return translation.Translate("pf_update_failed")
+		}
+		if !removed {
+			return translation.Translate("pf_not_found", symbol)
+		}
+		return translation.Translate("pf_removed", symbol)
+	}
+
+	amount, err := strconv.ParseFloat(args[1], 64)
+	if err != nil || amount <= 0 {
+		return translation.Translate("pf_invalid_amount")
+	}
+
+	remaining, found, err := database.ReducePosition(userID, *c.ID, amount)
+	if err != nil {
+		log.Error(err)
+		return translation.Translate("pf_update_failed")
+	}
+	if !found {
+		return translation.Translate("pf_not_found", symbol)
+	}
+	if remaining == 0 {
+		return translation.Translate("pf_removed", symbol)
+	}
+	return translation.Translate("pf_reduced", commands.FormatAmount(amount), symbol, commands.FormatAmount(remaining))
+}
+
+// handlePortfolioChart sends the portfolio value over the given range, 90 days by default
+func (b *Bot) handlePortfolioChart(u tgbotapi.Update, args []string) string {
+	if len(args) > 1 {
+		return translation.Translate("pf_command_usage")
+	}
+
+	timeRange := ""
+	if len(args) == 1 {
+		timeRange = strings.ToLower(args[0])
+	}
+	days, err := commands.ParsePortfolioRange(timeRange)
+	if err != nil {
+		return translation.Translate("pf_command_usage")
+	}
+
+	chartData, caption, err := commands.CommandPortfolioChart(u.Message.From.ID, days)
+	if err == commands.ErrNotEnoughPortfolioHistory {
+		return translation.Translate("pf_chart_not_enough_history")
+	}
+	if err != nil {
+		log.Error(err)
+		return translation.Translate("pf_update_failed")
+	}
+
+	b.sendChart(u.Message.Chat.ID, u.Message.MessageID, chartData, caption, nil)
+	return ""
+}
+
+// portfolioSummary returns the sender's allocations and returns without amounts or values
+func (b *Bot) portfolioSummary(u tgbotapi.Update) string {
+	positions, err := database.GetPortfolio(u.Message.From.ID)
+	if err != nil {
+		log.Error(err)
+		return translation.Translate("pf_update_failed")
+	}
+	if len(positions) == 0 {
+		return translation.Translate("pf_empty")
+	}
+
+	return commands.FormatPortfolioSummary(u.Message.From.FirstName, commands.ValuePortfolio(positions))
+}
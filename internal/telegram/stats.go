@@ -0,0 +1,30 @@
+package telegram
+
+import (
+	"coinpaprika-telegram-bot/internal/commands"
+	"coinpaprika-telegram-bot/lib/translation"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+// HandleStatsCommand handles /stats, replying with the risk statistics of a coin and optionally a returns histogram
+func (b *Bot) HandleStatsCommand(u tgbotapi.Update) string {
+	chatID := u.Message.Chat.ID
+
+	chartData, text, err := commands.CommandStats(chatID, u.Message.CommandArguments())
+	switch {
+	case err == commands.ErrInvalidStats:
+		return translation.Translate("stats_command_usage")
+	case err == commands.ErrNoStatsData:
+		return translation.Translate("stats_no_data")
+	case err != nil:
+		log.Error(err)
+		return translation.Translate("Coin not found")
+	}
+
+	if chartData == nil {
+		return text
+	}
+	b.sendChart(chatID, u.Message.MessageID, chartData, text, nil)
+	return ""
+}
@@ -1,6 +1,10 @@
 package telegram
 
-import tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+import (
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"sync"
+	"time"
+)
 
 // BotConfig configuration of the bot
 type BotConfig struct {
@@ -11,9 +15,39 @@ type BotConfig struct {
 
 // Bot telegram interaction client
 type Bot struct {
-	Bot              *tgbotapi.BotAPI
-	Config           BotConfig
-	messageTargetMap map[int]string // Map MessageID to Target Price
+	Bot               *tgbotapi.BotAPI
+	Config            BotConfig
+	messageTargetMap  map[int]string               // Map MessageID to Target Price
+	alertEditMap      map[chatMessage]pendingEdit  // Map edit prompt message to the edited alert
+	alertSpecMap      map[chatMessage]pendingAlert // Map coin picker message to the pending alert target and options
+	chartFileIDs      map[string]cachedChart       // Map coin ID to the last uploaded overview chart
+	chartRefreshes    map[chatMessage]time.Time    // Map chart message to its last refresh
+	chartFileIDsMutex sync.RWMutex
+}
+
+// chatMessage identifies a message, Telegram message IDs are only unique within a chat
+type chatMessage struct {
+	ChatID    int64
+	MessageID int
+}
+
+// pendingAlert the target and options of an alert waiting for its coin to be picked
+type pendingAlert struct {
+	Target    string
+	UserID    int64 // member who asked for the alert, 0 when unknown
+	CreatedAt time.Time
+}
+
+// pendingEdit an alert waiting for a new target to be replied to its edit prompt
+type pendingEdit struct {
+	AlertID   int64
+	CreatedAt time.Time
+}
+
+// cachedChart an uploaded chart photo which can be re-sent by its file ID
+type cachedChart struct {
+	FileID     string
+	UploadedAt time.Time
 }
 
 // Message a telegram message struct
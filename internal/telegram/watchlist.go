@@ -0,0 +1,119 @@
+package telegram
+
+import (
+	"coinpaprika-telegram-bot/internal/commands"
+	"coinpaprika-telegram-bot/internal/database"
+	"coinpaprika-telegram-bot/lib/helpers"
+	"coinpaprika-telegram-bot/lib/translation"
+	"github.com/coinpaprika/coinpaprika-api-go-client/v2/coinpaprika"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	log "github.com/sirupsen/logrus"
+	"strings"
+)
+
+// HandleWatchCommand handles /watch add and /watch remove, which edit the chat's watchlist shown by /wl.
+// The same watchlist selects the coins of the /events notifications.
+func (b *Bot) HandleWatchCommand(u tgbotapi.Update) string {
+	chatID := u.Message.Chat.ID
+	args := strings.Fields(u.Message.CommandArguments())
+	if len(args) < 2 {
+		return translation.Translate("watch_command_usage")
+	}
+
+	subcommand := strings.ToLower(args[0])
+	if subcommand != "add" && subcommand != "remove" && subcommand != "rm" {
+		return translation.Translate("watch_command_usage")
+	}
+	// The watchlist selects the coins of the chat's event notifications, so it is a setting like /events watch
+	if !b.isChatAdmin(u.Message) {
+		return translation.Translate("admin_only")
+	}
+
+	return updateWatchlist(chatID, subcommand == "add", args[1:])
+}
+
+// updateWatchlist adds coins to or removes them from the chat's watchlist, keeping it within MaxWatchlistCoins
+func updateWatchlist(chatID int64, add bool, queries []string) string {
+	watched, err := database.GetWatchlist(chatID)
+	if err != nil {
+		log.Error(err)
+		return translation.Translate("watch_update_failed")
+	}
+
+	var coins []*coinpaprika.Coin
+	var notFound []string
+	for _, query := range queries {
+		c, err := commands.ResolveCoin(chatID, query)
+		if err != nil {
+			log.Debugf("unable to resolve %s for /watch: %v", query, err)
+			notFound = append(notFound, helpers.EscapeMarkdownV2(query))
+			continue
+		}
+		coins = append(coins, c)
+	}
+
+	// The capacity is checked before saving anything, so a command over the limit leaves the watchlist unchanged
+	if add {
+		for _, c := range coins {
+			if !containsString(watched, *c.ID) {
+				watched = append(watched, *c.ID)
+			}
+		}
+		if len(watched) > commands.MaxWatchlistCoins {
+			return translation.Translate("watch_limit_reached", commands.MaxWatchlistCoins)
+		}
+	}
+
+	var names []string
+	for _, c := range coins {
+		if add {
+			err = database.AddToWatchlist(chatID, *c.ID)
+		} else {
+			err = database.RemoveFromWatchlist(chatID, *c.ID)
+		}
+		if err != nil {
+			log.Error(err)
+			return translation.Translate("watch_update_failed")
+		}
+		names = append(names, helpers.EscapeMarkdownV2(*c.Symbol))
+	}
+
+	var text string
+	if len(names) > 0 {
+		if add {
+			text = translation.Translate("watch_added", strings.Join(names, ", "))
+		} else {
+			text = translation.Translate("watch_removed", strings.Join(names, ", "))
+		}
+	}
+	if len(notFound) > 0 {
+		if text != "" {
+			text += "\n"
+		}
+		text += translation.Translate("cashtags_not_found", strings.Join(notFound, ", "))
+	}
+	return text
+}
+
+// HandleWatchlistCommand handles /wl, printing the chat's watchlist as a single table
+func (b *Bot) HandleWatchlistCommand(u tgbotapi.Update) string {
+	coinIDs, err := database.GetWatchlist(u.Message.Chat.ID)
+	if err != nil {
+		log.Error(err)
+		return translation.Translate("watch_update_failed")
+	}
+	if len(coinIDs) == 0 {
+		return translation.Translate("wl_empty")
+	}
+
+	return commands.FormatWatchlist(coinIDs)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
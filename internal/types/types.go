@@ -1,11 +1,57 @@
 package types
 
+import "time"
+
 type Alert struct {
-	ID           int64   `json:"id"`
-	ChatID       int64   `json:"chat_id"`
-	Ticker       string  `json:"ticker"`
-	Target       float64 `json:"target"`
-	CurrentPrice float64 `json:"current_price"`
-	AlertType    string  `json:"alert_type"` // e.g., "price, percent_change"
-	CreatedAt    string  `json:"created_at"`
+	ID              int64     `json:"id"`
+	ChatID          int64     `json:"chat_id"`
+	Ticker          string    `json:"ticker"`
+	Target          float64   `json:"target"`
+	CurrentPrice    float64   `json:"current_price"`
+	AlertType       string    `json:"alert_type"` // e.g., "price, percent_change"
+	CreatedAt       string    `json:"created_at"`
+	RepeatInterval  int64     `json:"repeat_interval"`   // cooldown in seconds between notifications, 0 fires once
+	Hysteresis      float64   `json:"hysteresis"`        // percent the price must move back across the threshold to re-arm
+	Armed           bool      `json:"armed"`             // false while a repeating alert waits to be re-armed
+	LastTriggeredAt time.Time `json:"last_triggered_at"` // zero until the first notification
+	Window          int64     `json:"window_seconds"`    // trailing window in seconds of a move alert
+	QuoteTicker     string    `json:"quote_ticker"`      // quote coin of a pair alert on the Ticker/QuoteTicker ratio
+	ExpiresAt       time.Time `json:"expires_at"`        // zero for alerts which never expire
+	NotifyExpiry    bool      `json:"notify_expiry"`     // notify the chat when the alert expires without triggering
+	UserID          int64     `json:"user_id"`           // member who set the alert, 0 for alerts set before creators were recorded
+}
+
+type GlobalSnapshot struct {
+	MarketCap    float64   `json:"market_cap"`
+	Volume24h    float64   `json:"volume_24h"`
+	BTCDominance float64   `json:"btc_dominance"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type Alias struct {
+	ChatID int64  `json:"chat_id"`
+	Alias  string `json:"alias"`
+	CoinID string `json:"coin_id"`
+}
+
+type Position struct {
+	UserID    int64   `json:"user_id"`
+	CoinID    string  `json:"coin_id"`
+	Amount    float64 `json:"amount"`
+	CostBasis float64 `json:"cost_basis"` // average buy price per coin in USD
+}
+
+type PortfolioSnapshot struct {
+	UserID int64   `json:"user_id"`
+	Date   string  `json:"snapshot_date"` // YYYY-MM-DD in UTC
+	CoinID string  `json:"coin_id"`
+	Value  float64 `json:"value"`
+}
+
+type Digest struct {
+	ChatID    int64     `json:"chat_id"`
+	Frequency string    `json:"frequency"`   // daily or hourly
+	TimeOfDay string    `json:"time_of_day"` // HH:MM in the timezone, only the minute is used by hourly digests
+	Timezone  string    `json:"timezone"`
+	NextRunAt time.Time `json:"next_run_at"`
 }
@@ -5,6 +5,7 @@ import (
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/text/language"
 	"golang.org/x/text/message"
+	"math"
 	"strings"
 	"time"
 )
@@ -18,6 +19,12 @@ func EscapeMarkdownV2(text string) string {
 	return text
 }
 
+// EscapeMarkdownV2Link escapes the characters not allowed inside the (...) part of an inline link
+func EscapeMarkdownV2Link(link string) string {
+	link = strings.ReplaceAll(link, "\\", "\\\\")
+	return strings.ReplaceAll(link, ")", "\\)")
+}
+
 func FormatPriceUS(price float64, escapeMarkdown bool) string {
 	decimals := 6
 
@@ -71,3 +78,60 @@ func FormatDate(dateString string) string {
 func FormatPercentage(value float64) string {
 	return fmt.Sprintf("%.1f", value)
 }
+
+func FormatCompactUS(value float64) string {
+	abs := math.Abs(value)
+	switch {
+	case abs >= 1e12:
+		return fmt.Sprintf("%.2fT", value/1e12)
+	case abs >= 1e9:
+		return fmt.Sprintf("%.2fB", value/1e9)
+	case abs >= 1e6:
+		return fmt.Sprintf("%.2fM", value/1e6)
+	case abs >= 1e3:
+		return fmt.Sprintf("%.2fK", value/1e3)
+	}
+	return fmt.Sprintf("%.2f", value)
+}
+
+// FormatInterval formats a duration in its largest whole unit, e.g. "30m", "4h" or "2d"
+func FormatInterval(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour && d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	case d >= time.Hour && d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	}
+	return fmt.Sprintf("%dm", d/time.Minute)
+}
+
+// Levenshtein returns the edit distance between two strings
+func Levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 {
+		return len(rb)
+	}
+	if len(rb) == 0 {
+		return len(ra)
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
@@ -2,6 +2,16 @@ package translation
 
 import (
 	"github.com/leonelquinteros/gotext"
+	"os"
+	"sort"
+	"sync"
+)
+
+const localesDir = "locales"
+
+var (
+	locales      = make(map[string]*gotext.Locale)
+	localesMutex = sync.Mutex{}
 )
 
 func GetLanguage() string {
@@ -17,3 +27,34 @@ func GetLanguage() string {
 func Translate(msgID string, vars ...interface{}) string {
 	return gotext.Get(msgID, vars...)
 }
+
+// TranslateIn translates a message into the given language instead of the configured one
+func TranslateIn(lang, msgID string, vars ...interface{}) string {
+	localesMutex.Lock()
+	l, found := locales[lang]
+	if !found {
+		l = gotext.NewLocale(localesDir, lang)
+		l.AddDomain("default")
+		locales[lang] = l
+	}
+	localesMutex.Unlock()
+
+	return l.Get(msgID, vars...)
+}
+
+// GetLanguages returns the languages which have a catalog in the locales directory
+func GetLanguages() []string {
+	entries, err := os.ReadDir(localesDir)
+	if err != nil {
+		return nil
+	}
+
+	var languages []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			languages = append(languages, entry.Name())
+		}
+	}
+	sort.Strings(languages)
+	return languages
+}